@@ -7,12 +7,36 @@ import (
 
 const indententionCharacter = "  "
 
+// voidElements lists html elements that can never have any children
+var voidElements = map[string]bool{
+	"area":   true,
+	"base":   true,
+	"br":     true,
+	"col":    true,
+	"embed":  true,
+	"hr":     true,
+	"img":    true,
+	"input":  true,
+	"link":   true,
+	"meta":   true,
+	"param":  true,
+	"source": true,
+	"track":  true,
+	"wbr":    true,
+}
+
+// IsVoidElement checks if tag is an html void element like <br> or <input>
+func IsVoidElement(tag string) bool {
+	return voidElements[tag]
+}
+
 // Node represents node interface
 type Node interface {
 	nodeType()
 	Tag() string
 	Attributes() []Attribute
 	Children() []Node
+	Position() Position
 	String() string
 	indentedString(int) string
 }
@@ -23,6 +47,16 @@ type Attribute struct {
 	Value string
 }
 
+// Position represents location in the template source, both line and column start at 1
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (pos Position) String() string {
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+}
+
 // Root represents AST tree root node
 type Root struct {
 	HTMLChildren []Node
@@ -32,6 +66,7 @@ func (rt *Root) nodeType()               {}
 func (rt *Root) Tag() string             { return "root" }
 func (rt *Root) Attributes() []Attribute { return []Attribute{} }
 func (rt *Root) Children() []Node        { return rt.HTMLChildren }
+func (rt *Root) Position() Position      { return Position{Line: 1, Column: 1} }
 func (rt *Root) String() string {
 	var out strings.Builder
 
@@ -49,12 +84,14 @@ type Element struct {
 	HTMLTag        string
 	HTMLAttributes []Attribute
 	HTMLChildren   []Node
+	Pos            Position
 }
 
 func (el *Element) nodeType()               {}
 func (el *Element) Tag() string             { return el.HTMLTag }
 func (el *Element) Attributes() []Attribute { return el.HTMLAttributes }
 func (el *Element) Children() []Node        { return el.HTMLChildren }
+func (el *Element) Position() Position      { return el.Pos }
 func (el *Element) String() string {
 	return el.indentedString(0)
 }
@@ -83,3 +120,82 @@ func (el *Element) indentedString(level int) string {
 
 	return out.String()
 }
+
+// Text represents html text content
+type Text struct {
+	Content string
+	Pos     Position
+}
+
+func (tx *Text) nodeType()               {}
+func (tx *Text) Tag() string             { return "#text" }
+func (tx *Text) Attributes() []Attribute { return []Attribute{} }
+func (tx *Text) Children() []Node        { return []Node{} }
+func (tx *Text) Position() Position      { return tx.Pos }
+func (tx *Text) String() string {
+	return tx.indentedString(0)
+}
+
+func (tx *Text) indentedString(level int) string {
+	var out strings.Builder
+
+	out.WriteString(strings.Repeat(indententionCharacter, level))
+	out.WriteString(fmt.Sprintf("%q", tx.Content))
+	out.WriteString("\n")
+
+	return out.String()
+}
+
+// Conditional represents group of sibling elements
+// marked with w-if, w-else-if and w-else directives
+type Conditional struct {
+	Branches []*ConditionalBranch
+	Pos      Position
+}
+
+// ConditionalBranch represents single conditional branch,
+// empty condition is used for the w-else branch
+type ConditionalBranch struct {
+	Condition string
+	Node      Node
+}
+
+func (cn *Conditional) nodeType()               {}
+func (cn *Conditional) Tag() string             { return "#if" }
+func (cn *Conditional) Attributes() []Attribute { return []Attribute{} }
+func (cn *Conditional) Position() Position      { return cn.Pos }
+func (cn *Conditional) Children() []Node {
+	children := make([]Node, 0, len(cn.Branches))
+	for _, branch := range cn.Branches {
+		children = append(children, branch.Node)
+	}
+
+	return children
+}
+func (cn *Conditional) String() string {
+	return cn.indentedString(0)
+}
+
+func (cn *Conditional) indentedString(level int) string {
+	var out strings.Builder
+
+	ident := strings.Repeat(indententionCharacter, level)
+
+	for i, branch := range cn.Branches {
+		out.WriteString(ident)
+		switch {
+		case i == 0:
+			out.WriteString(fmt.Sprintf("#if %s\n", branch.Condition))
+		case branch.Condition != "":
+			out.WriteString(fmt.Sprintf("#else-if %s\n", branch.Condition))
+		default:
+			out.WriteString("#else\n")
+		}
+		out.WriteString(branch.Node.indentedString(level + 1))
+	}
+
+	out.WriteString(ident)
+	out.WriteString("#end\n")
+
+	return out.String()
+}
@@ -10,7 +10,11 @@ import (
 	uuid "github.com/satori/go.uuid"
 )
 
-const tagKey = "wasm"
+const (
+	tagKey            = "wasm"
+	nameTagPrefix     = "name="
+	computedTagPrefix = "computed="
+)
 
 type ComponentInput interface {
 	Init() error
@@ -23,7 +27,16 @@ type Wrapper struct {
 	setters  map[string]func(interface{}) error
 	getters  map[string]func() interface{}
 	handlers map[string]func(*event.Event)
+	methods  map[string]func() interface{}
+	watchers map[string]func(old, new reflect.Value)
 	props    map[string]string
+	// required holds names of the props that have to be set by the parent
+	required   []string
+	validators map[string]func(reflect.Value) error
+	computed   map[string]bool
+	// version changes every time instance is changed, computed getters are cached until then
+	version int
+	notify  func()
 }
 
 func Wasmify(comp interface{}) (*Wrapper, error) {
@@ -45,40 +58,81 @@ func Wasmify(comp interface{}) (*Wrapper, error) {
 		return nil, errors.New("Wasmify only accepts structs that implement ComponentInput interface")
 	}
 
+	// tags and methods are checked once, so broken components are reported at registration
+	_, err := wrapper.build(reflect.New(val.Type()).Interface())
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid component %s", val.Type())
+	}
+
 	return wrapper, nil
 }
 
 func (w *Wrapper) Instance() (*Wrapper, error) {
+	instance := reflect.New(reflect.ValueOf(w.input).Elem().Type()).Interface()
+	in, ok := instance.(ComponentInput)
+	if !ok {
+		return nil, fmt.Errorf("Could not cast type %s to ComponentInput interface", reflect.ValueOf(instance).Elem().Type())
+	}
+
+	err := in.Init()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not initialize %s", reflect.ValueOf(instance).Elem().Type())
+	}
+
+	result, err := w.build(instance)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not create Wrapper instance")
+	}
+
+	result.uuid = uuid.NewV4().String()
+
+	return result, nil
+}
+
+// build creates wrapper of the instance with getters, setters, props, handlers and computed getters
+// found by reflection
+func (w *Wrapper) build(instance interface{}) (*Wrapper, error) {
 	wrapperCpy := *w
 	result := &wrapperCpy
 
+	result.instance = instance
 	result.getters = make(map[string]func() interface{}, 0)
 	result.setters = make(map[string]func(interface{}) error, 0)
 	result.props = make(map[string]string, 0)
 	result.handlers = make(map[string]func(*event.Event), 0)
-
-	result.instance = reflect.New(reflect.ValueOf(result.input).Elem().Type()).Interface()
-	in, ok := result.instance.(ComponentInput)
-	if ok {
-		in.Init()
-	} else {
-		return nil, fmt.Errorf("Could not cast type %s to ComponentInput interface", reflect.ValueOf(result.instance).Elem().Type())
-	}
+	result.methods = make(map[string]func() interface{}, 0)
+	result.watchers = make(map[string]func(old, new reflect.Value), 0)
+	result.validators = make(map[string]func(reflect.Value) error, 0)
+	result.computed = make(map[string]bool, 0)
+	result.required = nil
 
 	err := result.constructGetters()
 	if err != nil {
-		return nil, errors.Wrap(err, "Could not create Wrapper instance")
+		return nil, err
 	}
 
 	err = result.constructSetters()
 	if err != nil {
-		return nil, errors.Wrap(err, "Could not create Wrapper instance")
+		return nil, err
+	}
+
+	err = result.findWatchers()
+	if err != nil {
+		return nil, err
+	}
+
+	err = result.findProps()
+	if err != nil {
+		return nil, err
 	}
 
-	result.findProps()
 	result.findHandlers()
+	result.findMethods()
 
-	result.uuid = uuid.NewV4().String()
+	err = result.findComputed()
+	if err != nil {
+		return nil, err
+	}
 
 	return result, nil
 }
@@ -89,6 +143,9 @@ func (w *Wrapper) constructGetters() error {
 	for i := 0; i < val.NumField(); i++ {
 		typeField := val.Type().Field(i)
 		name := typeField.Name
+		if typeField.PkgPath != "" {
+			continue
+		}
 
 		getter := func() interface{} {
 			return reflect.ValueOf(w.instance).Elem().FieldByName(name).Interface()
@@ -106,16 +163,25 @@ func (w *Wrapper) constructSetters() error {
 	for i := 0; i < val.NumField(); i++ {
 		typeField := val.Type().Field(i)
 		name := typeField.Name
+		if typeField.PkgPath != "" {
+			continue
+		}
 
 		setter := func(in interface{}) error {
 			targetField := reflect.ValueOf(w.instance).Elem().FieldByName(name)
-			input := reflect.ValueOf(in)
+			input, err := convert(in, targetField.Type())
+			if err != nil {
+				return err
+			}
 
-			if targetField.Type() != input.Type() {
-				return errors.New(fmt.Sprintf("Mismatched target and input types %s != %s", input.Type(), targetField.Type()))
+			err = w.validate(name, input)
+			if err != nil {
+				return err
 			}
 
-			targetField.Set(input)
+			w.track(func() {
+				targetField.Set(input)
+			})
 
 			return nil
 		}
@@ -126,19 +192,45 @@ func (w *Wrapper) constructSetters() error {
 	return nil
 }
 
-func (w *Wrapper) findProps() {
+// findProps collects fields tagged with wasm:"prop", attribute name of the prop
+// is kebab-cased field name (UserID becomes user-id) unless set with name=attribute option
+func (w *Wrapper) findProps() error {
 	val := reflect.ValueOf(w.instance).Elem()
 
 	for i := 0; i < val.NumField(); i++ {
 		typeField := val.Type().Field(i)
 		ts, _ := typeField.Tag.Lookup(tagKey)
 		tags := strings.Split(ts, ",")
+		isAProp := false
+		name := kebab(typeField.Name)
 		for _, tag := range tags {
 			if tag == "prop" {
-				w.props[strings.ToLower(typeField.Name)] = typeField.Name
+				isAProp = true
+			}
+			if strings.HasPrefix(tag, nameTagPrefix) {
+				name = strings.TrimPrefix(tag, nameTagPrefix)
 			}
 		}
+
+		if !isAProp {
+			continue
+		}
+
+		if name == "" || name != strings.ToLower(name) {
+			return fmt.Errorf("Prop name %q of field %s should be a non empty lower case attribute name", name, typeField.Name)
+		}
+		if other, ok := w.props[name]; ok {
+			return fmt.Errorf("Prop %s of field %s collides with field %s", name, typeField.Name, other)
+		}
+		w.props[name] = typeField.Name
+
+		err := w.applyPropOptions(name, typeField, tags)
+		if err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
 func (w *Wrapper) findHandlers() {
@@ -149,10 +241,80 @@ func (w *Wrapper) findHandlers() {
 		if strings.HasPrefix(method.Name, "Handle") {
 			w.handlers[method.Name] = func(e *event.Event) {
 				arg := reflect.ValueOf(e)
-				method.Func.Call([]reflect.Value{val, arg})
+				w.track(func() {
+					method.Func.Call([]reflect.Value{val, arg})
+				})
+				if w.notify != nil {
+					w.notify()
+				}
+			}
+		}
+	}
+}
+
+// findMethods collects methods without arguments and with a single result,
+// those are available for calls from template expressions
+func (w *Wrapper) findMethods() {
+	val := reflect.ValueOf(w.instance)
+
+	for i := 0; i < val.NumMethod(); i++ {
+		method := val.Type().Method(i)
+		if method.Name == "Init" || method.Type.NumIn() != 1 || method.Type.NumOut() != 1 {
+			continue
+		}
+
+		w.methods[method.Name] = func() interface{} {
+			return method.Func.Call([]reflect.Value{val})[0].Interface()
+		}
+	}
+}
+
+// findComputed exposes methods listed with wasm:"computed=Method|Other" tag
+// of a blank marker field as computed getters, so templates could use them just like fields
+func (w *Wrapper) findComputed() error {
+	typ := reflect.TypeOf(w.instance).Elem()
+
+	for i := 0; i < typ.NumField(); i++ {
+		ts, _ := typ.Field(i).Tag.Lookup(tagKey)
+		for _, tag := range strings.Split(ts, ",") {
+			if !strings.HasPrefix(tag, computedTagPrefix) {
+				continue
+			}
+
+			for _, name := range strings.Split(strings.TrimPrefix(tag, computedTagPrefix), "|") {
+				method, ok := w.methods[name]
+				if !ok {
+					return fmt.Errorf("Computed getter %s should be a method without arguments and with a single result", name)
+				}
+				w.getters[name] = w.cached(method)
+				w.computed[name] = true
 			}
 		}
 	}
+
+	return nil
+}
+
+// cached memoizes result of the computed getter until instance is changed
+// by a setter, a handler or a lifecycle hook
+func (w *Wrapper) cached(f func() interface{}) func() interface{} {
+	var value interface{}
+	version := -1
+
+	return func() interface{} {
+		if version != w.version {
+			value = f()
+			version = w.version
+		}
+
+		return value
+	}
+}
+
+// Invalidate drops cached values of computed getters,
+// it is called before refresh since instance could be changed directly
+func (w *Wrapper) Invalidate() {
+	w.version++
 }
 
 func (w *Wrapper) Getter(name string) (func() interface{}, bool) {
@@ -167,6 +329,34 @@ func (w *Wrapper) Setter(name string) (func(interface{}) error, bool) {
 	return f, ok
 }
 
+// FieldType returns type of the field or result type of the computed getter
+func (w *Wrapper) FieldType(name string) (reflect.Type, bool) {
+	field, ok := reflect.TypeOf(w.instance).Elem().FieldByName(name)
+	if !ok || field.PkgPath != "" {
+		if !w.computed[name] {
+			return nil, false
+		}
+		return w.MethodType(name)
+	}
+
+	return field.Type, true
+}
+
+func (w *Wrapper) Method(name string) (func() interface{}, bool) {
+	m, ok := w.methods[name]
+	return m, ok
+}
+
+func (w *Wrapper) MethodType(name string) (reflect.Type, bool) {
+	if _, ok := w.methods[name]; !ok {
+		return nil, false
+	}
+
+	method, _ := reflect.TypeOf(w.instance).MethodByName(name)
+
+	return method.Type.Out(0), true
+}
+
 func (w *Wrapper) IsAProp(name string) (string, bool) {
 	field, ok := w.props[name]
 	return field, ok
@@ -182,6 +372,11 @@ func (w *Wrapper) Handler(name string) (func(*event.Event), bool) {
 	return h, ok
 }
 
+// SetNotifier sets function that is called after every handler of the instance
+func (w *Wrapper) SetNotifier(f func()) {
+	w.notify = f
+}
+
 func (w *Wrapper) UUID() string {
 	return w.uuid
 }
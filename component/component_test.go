@@ -1,9 +1,14 @@
 package component
 
 import (
+	"fmt"
+	"math"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/Gonzih/wasm-mk2/event"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -124,3 +129,454 @@ func TestHandler(t *testing.T) {
 
 	require.Equal(t, 11, getter())
 }
+
+func (c *MyDiv) Double() int {
+	return c.Counter * 2
+}
+
+func TestMethods(t *testing.T) {
+	w, err := Wasmify(&MyDiv{})
+	require.Nil(t, err)
+
+	wrapper, err := w.Instance()
+	require.Nil(t, err)
+
+	method, ok := wrapper.Method("Double")
+	require.True(t, ok)
+	require.Equal(t, 20, method())
+
+	typ, ok := wrapper.MethodType("Double")
+	require.True(t, ok)
+	require.Equal(t, reflect.TypeOf(0), typ)
+
+	_, ok = wrapper.Method("Init")
+	require.False(t, ok)
+	_, ok = wrapper.Method("HandleClick")
+	require.False(t, ok)
+
+	typ, ok = wrapper.FieldType("Input")
+	require.True(t, ok)
+	require.Equal(t, reflect.TypeOf(""), typ)
+}
+
+type Broken struct{}
+
+var errBroken = errors.New("broken")
+
+func (c *Broken) Init() error { return errBroken }
+
+func TestInstanceInitError(t *testing.T) {
+	w, err := Wasmify(&Broken{})
+	require.Nil(t, err)
+
+	wrapper, err := w.Instance()
+	require.Nil(t, wrapper)
+	require.Equal(t, errBroken, errors.Cause(err))
+	require.Contains(t, err.Error(), "component.Broken")
+}
+
+type Address struct {
+	City string
+}
+
+type Person struct {
+	_       struct{} `wasm:"computed=FullName|Location"`
+	First   string
+	Last    string
+	Address *Address
+	calls   int
+	saves   int
+}
+
+func (c *Person) Init() error {
+	c.First = "Ada"
+	c.Last = "Lovelace"
+	c.Address = &Address{City: "London"}
+	return nil
+}
+
+func (c *Person) FullName() string {
+	c.calls++
+	return c.First + " " + c.Last
+}
+
+func (c *Person) Location() string {
+	return c.Address.City
+}
+
+func (c *Person) Save() error {
+	c.saves++
+	return nil
+}
+
+func (c *Person) HandleMove(e *event.Event) {
+	c.Address.City = "Paris"
+}
+
+type BadComputed struct {
+	_ struct{} `wasm:"computed=Missing"`
+}
+
+func (c *BadComputed) Init() error { return nil }
+
+func TestComputed(t *testing.T) {
+	w, err := Wasmify(&Person{})
+	require.Nil(t, err)
+
+	wrapper, err := w.Instance()
+	require.Nil(t, err)
+	person := wrapper.instance.(*Person)
+
+	getter, ok := wrapper.Getter("FullName")
+	require.True(t, ok)
+	require.Equal(t, "Ada Lovelace", getter())
+	require.Equal(t, "Ada Lovelace", getter())
+	require.Equal(t, 1, person.calls)
+
+	setter, ok := wrapper.Setter("Last")
+	require.True(t, ok)
+	require.Nil(t, setter("Byron"))
+	require.Equal(t, "Ada Byron", getter())
+	require.Equal(t, "Ada Byron", getter())
+	require.Equal(t, 2, person.calls)
+
+	location, ok := wrapper.Getter("Location")
+	require.True(t, ok)
+	require.Equal(t, "London", location())
+	handler, _ := wrapper.Handler("HandleMove")
+	handler(&event.Event{})
+	require.Equal(t, "Paris", location())
+
+	typ, ok := wrapper.FieldType("FullName")
+	require.True(t, ok)
+	require.Equal(t, reflect.TypeOf(""), typ)
+
+	_, ok = wrapper.Getter("Save")
+	require.False(t, ok)
+	_, ok = wrapper.FieldType("Save")
+	require.False(t, ok)
+	_, ok = wrapper.Method("Save")
+	require.True(t, ok)
+	require.Equal(t, 0, person.saves)
+
+	_, err = Wasmify(&BadComputed{})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "Computed getter Missing should be a method")
+}
+
+type Watched struct {
+	Counter int    `wasm:"state"`
+	Name    string `wasm:"prop,watch=NameChanged"`
+	changes []string
+}
+
+func (c *Watched) Init() error { return nil }
+
+func (c *Watched) WatchCounter(old, new int) {
+	c.changes = append(c.changes, fmt.Sprintf("Counter %d -> %d", old, new))
+}
+
+func (c *Watched) NameChanged(old, new string) {
+	c.changes = append(c.changes, fmt.Sprintf("Name %q -> %q", old, new))
+}
+
+func (c *Watched) HandleClick(e *event.Event) {
+	c.Counter++
+}
+
+type BadWatcher struct {
+	Counter int
+}
+
+func (c *BadWatcher) Init() error                  { return nil }
+func (c *BadWatcher) WatchCounter(old, new string) {}
+
+func TestWatchers(t *testing.T) {
+	w, err := Wasmify(&Watched{})
+	require.Nil(t, err)
+
+	wrapper, err := w.Instance()
+	require.Nil(t, err)
+	watched := wrapper.instance.(*Watched)
+
+	setter, _ := wrapper.Setter("Name")
+	require.Nil(t, setter("Ada"))
+	require.Nil(t, setter("Ada"))
+
+	handler, _ := wrapper.Handler("HandleClick")
+	handler(&event.Event{})
+
+	setter, _ = wrapper.Setter("Counter")
+	require.Nil(t, setter(5))
+
+	require.Equal(t, []string{`Name "" -> "Ada"`, "Counter 0 -> 1", "Counter 1 -> 5"}, watched.changes)
+
+	_, err = Wasmify(&BadWatcher{})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "Watcher WatchCounter of field Counter")
+}
+
+type Level int
+
+func (l *Level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %s", text)
+	}
+	return nil
+}
+
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type Typed struct {
+	Count   int
+	Size    uint8
+	Ratio   float64
+	Enabled bool
+	Delay   time.Duration
+	Level   Level
+	At      Point
+	Tags    []string
+	Label   string
+}
+
+func (c *Typed) Init() error { return nil }
+
+func TestSetterConversion(t *testing.T) {
+	w, err := Wasmify(&Typed{})
+	require.Nil(t, err)
+	wrapper, err := w.Instance()
+	require.Nil(t, err)
+	typed := wrapper.instance.(*Typed)
+
+	set := func(name string, v interface{}) error {
+		setter, ok := wrapper.Setter(name)
+		require.True(t, ok)
+		return setter(v)
+	}
+
+	require.Nil(t, set("Count", "42"))
+	require.Nil(t, set("Size", "200"))
+	require.Nil(t, set("Ratio", "0.5"))
+	require.Nil(t, set("Enabled", "true"))
+	require.Nil(t, set("Delay", "1m30s"))
+	require.Nil(t, set("Level", "high"))
+	require.Nil(t, set("At", `{"x": 1, "y": 2}`))
+	require.Nil(t, set("Tags", `["a", "b"]`))
+	require.Nil(t, set("Label", 7))
+	require.Equal(t, Typed{
+		Count:   42,
+		Size:    200,
+		Ratio:   0.5,
+		Enabled: true,
+		Delay:   90 * time.Second,
+		Level:   2,
+		At:      Point{1, 2},
+		Tags:    []string{"a", "b"},
+		Label:   "7",
+	}, *typed)
+
+	require.Nil(t, set("Ratio", 3))
+	require.Equal(t, 3.0, typed.Ratio)
+	require.Nil(t, set("Count", 7.0))
+	require.Nil(t, set("Count", int64(42)))
+	require.Nil(t, set("Size", 200))
+
+	err = set("Count", 3.7)
+	require.NotNil(t, err)
+	require.Equal(t, "Could not convert 3.7 of type float64 to int without loss", err.Error())
+	err = set("Size", 300)
+	require.NotNil(t, err)
+	require.Equal(t, "Could not convert 300 of type int to uint8 without loss", err.Error())
+	require.NotNil(t, set("Size", -1))
+	require.NotNil(t, set("Count", uint64(math.MaxUint64)))
+
+	err = set("Count", "forty two")
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), `Could not convert "forty two" to int`)
+	require.Equal(t, 42, typed.Count)
+
+	require.NotNil(t, set("Size", "-1"))
+	require.NotNil(t, set("Level", "medium"))
+	require.NotNil(t, set("At", "{"))
+	require.NotNil(t, set("Enabled", 1))
+	require.Equal(t, uint8(200), typed.Size)
+}
+
+type Button struct {
+	Label string        `wasm:"prop,required"`
+	Size  string        `wasm:"prop,default=m,oneof=s|m|l"`
+	Count int           `wasm:"prop,default=1,validate=CheckCount"`
+	Title string        `wasm:"prop"`
+	Delay time.Duration `wasm:"prop,default=1s"`
+}
+
+func (c *Button) Init() error {
+	c.Delay = time.Minute
+	return nil
+}
+
+func (c *Button) CheckCount(n int) error {
+	if n < 0 {
+		return errors.New("Count should not be negative")
+	}
+	return nil
+}
+
+func (c *Button) ValidateTitle(title string) error {
+	if len(title) > 5 {
+		return errors.New("Title is too long")
+	}
+	return nil
+}
+
+type BadDefault struct {
+	Count int `wasm:"prop,default=many"`
+}
+
+func (c *BadDefault) Init() error { return nil }
+
+type BadValidator struct {
+	Count int `wasm:"prop,validate=Missing"`
+}
+
+func (c *BadValidator) Init() error { return nil }
+
+type InvalidDefault struct {
+	Size string `wasm:"prop,oneof=s|m,default=xl"`
+}
+
+func (c *InvalidDefault) Init() error { return nil }
+
+type RejectedDefault struct {
+	Count int `wasm:"prop,default=-1"`
+}
+
+func (c *RejectedDefault) Init() error { return nil }
+
+func (c *RejectedDefault) ValidateCount(count int) error {
+	if count < 0 {
+		return errors.New("Count should not be negative")
+	}
+	return nil
+}
+
+func TestPropOptions(t *testing.T) {
+	w, err := Wasmify(&Button{})
+	require.Nil(t, err)
+	wrapper, err := w.Instance()
+	require.Nil(t, err)
+	button := wrapper.instance.(*Button)
+
+	require.Equal(t, []string{"label"}, wrapper.RequiredProps())
+	require.Equal(t, "m", button.Size)
+	require.Equal(t, 1, button.Count)
+	require.Equal(t, time.Minute, button.Delay)
+
+	set := func(name string, v interface{}) error {
+		setter, ok := wrapper.Setter(name)
+		require.True(t, ok)
+		return setter(v)
+	}
+
+	require.Nil(t, set("Size", "l"))
+	err = set("Size", "xl")
+	require.NotNil(t, err)
+	require.Equal(t, `Value "xl" is not one of s|m|l`, err.Error())
+	require.Equal(t, "l", button.Size)
+
+	require.Nil(t, set("Count", "3"))
+	require.Equal(t, "Count should not be negative", set("Count", "-3").Error())
+	require.Equal(t, 3, button.Count)
+
+	require.Nil(t, set("Title", "short"))
+	require.Equal(t, "Title is too long", set("Title", "too long").Error())
+	require.Equal(t, "short", button.Title)
+
+	_, err = Wasmify(&BadDefault{})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "Invalid default value of field Count")
+
+	_, err = Wasmify(&BadValidator{})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "Could not find validator Missing of field Count")
+
+	_, err = Wasmify(&InvalidDefault{})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), `Invalid default value of field Size: Value "xl" is not one of s|m`)
+
+	_, err = Wasmify(&RejectedDefault{})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "Invalid default value of field Count: Count should not be negative")
+}
+
+func TestKebab(t *testing.T) {
+	for in, out := range map[string]string{
+		"Label":     "label",
+		"UserID":    "user-id",
+		"HTMLTitle": "html-title",
+		"PageSize":  "page-size",
+		"Item2Name": "item2-name",
+		"ID":        "id",
+		"URLs":      "urls",
+		"IDs":       "ids",
+		"UserIDs":   "user-ids",
+		"IDsByName": "ids-by-name",
+		"HTMLStyle": "html-style",
+	} {
+		require.Equal(t, out, kebab(in), in)
+	}
+}
+
+type Profile struct {
+	UserID   string `wasm:"prop"`
+	FullName string `wasm:"prop,name=name"`
+}
+
+func (c *Profile) Init() error { return nil }
+
+type Colliding struct {
+	UserID  string `wasm:"prop"`
+	Account string `wasm:"prop,name=user-id"`
+}
+
+func (c *Colliding) Init() error { return nil }
+
+type UpperName struct {
+	Account string `wasm:"prop,name=accountId"`
+}
+
+func (c *UpperName) Init() error { return nil }
+
+func TestPropNames(t *testing.T) {
+	w, err := Wasmify(&Profile{})
+	require.Nil(t, err)
+	wrapper, err := w.Instance()
+	require.Nil(t, err)
+
+	field, ok := wrapper.IsAProp("user-id")
+	require.True(t, ok)
+	require.Equal(t, "UserID", field)
+	_, ok = wrapper.IsAProp("userid")
+	require.False(t, ok)
+	field, ok = wrapper.IsAProp("name")
+	require.True(t, ok)
+	require.Equal(t, "FullName", field)
+	_, ok = wrapper.IsAProp("full-name")
+	require.False(t, ok)
+
+	_, err = Wasmify(&Colliding{})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "Prop user-id of field Account collides with field UserID")
+
+	_, err = Wasmify(&UpperName{})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), `Prop name "accountId" of field Account`)
+}
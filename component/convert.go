@@ -0,0 +1,167 @@
+package component
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// convert converts value to the given type,
+// numbers are converted between numeric types unless value does not fit, any value is formatted in to string,
+// strings are parsed in to numbers, booleans, durations,
+// encoding.TextUnmarshaler implementations and JSON decoded structs, maps and slices
+func convert(in interface{}, t reflect.Type) (reflect.Value, error) {
+	input := reflect.ValueOf(in)
+	if !input.IsValid() {
+		return reflect.Zero(t), nil
+	}
+
+	if input.Type().AssignableTo(t) {
+		return input, nil
+	}
+
+	s, ok := in.(string)
+	if !ok {
+		if t.Kind() == reflect.String {
+			return reflect.ValueOf(fmt.Sprint(in)).Convert(t), nil
+		}
+		if isNumber(input.Kind()) && isNumber(t.Kind()) {
+			return convertNumber(input, t)
+		}
+		return input, fmt.Errorf("Mismatched target and input types %s != %s", input.Type(), t)
+	}
+
+	out, err := parse(s, t)
+	if err != nil {
+		return input, fmt.Errorf("Could not convert %q to %s: %s", s, t, err)
+	}
+
+	return out, nil
+}
+
+func parse(s string, t reflect.Type) (reflect.Value, error) {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		out := reflect.New(t)
+		err := out.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		return out.Elem(), err
+	}
+
+	if t == durationType {
+		d, err := time.ParseDuration(s)
+		return reflect.ValueOf(d), err
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, t.Bits())
+		return reflect.ValueOf(n).Convert(t), err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, t.Bits())
+		return reflect.ValueOf(n).Convert(t), err
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		return reflect.ValueOf(f).Convert(t), err
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		return reflect.ValueOf(b).Convert(t), err
+	case reflect.String:
+		return reflect.ValueOf(s).Convert(t), nil
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Ptr:
+		out := reflect.New(t)
+		err := json.Unmarshal([]byte(s), out.Interface())
+		return out.Elem(), err
+	}
+
+	return reflect.Value{}, fmt.Errorf("unsupported type")
+}
+
+// convertNumber converts number to another numeric type,
+// values that overflow the type or lose fractional part are rejected
+func convertNumber(input reflect.Value, t reflect.Type) (reflect.Value, error) {
+	out := reflect.New(t).Elem()
+	fail := fmt.Errorf("Could not convert %v of type %s to %s without loss", input.Interface(), input.Type(), t)
+
+	switch {
+	case isInt(t.Kind()):
+		var n int64
+		switch {
+		case isInt(input.Kind()):
+			n = input.Int()
+		case isUint(input.Kind()):
+			if input.Uint() > math.MaxInt64 {
+				return input, fail
+			}
+			n = int64(input.Uint())
+		default:
+			f := input.Float()
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return input, fail
+			}
+			n = int64(f)
+		}
+		if out.OverflowInt(n) {
+			return input, fail
+		}
+		out.SetInt(n)
+	case isUint(t.Kind()):
+		var n uint64
+		switch {
+		case isInt(input.Kind()):
+			if input.Int() < 0 {
+				return input, fail
+			}
+			n = uint64(input.Int())
+		case isUint(input.Kind()):
+			n = input.Uint()
+		default:
+			f := input.Float()
+			if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+				return input, fail
+			}
+			n = uint64(f)
+		}
+		if out.OverflowUint(n) {
+			return input, fail
+		}
+		out.SetUint(n)
+	default:
+		f := input.Convert(reflect.TypeOf(float64(0))).Float()
+		if out.OverflowFloat(f) {
+			return input, fail
+		}
+		out.SetFloat(f)
+	}
+
+	return out, nil
+}
+
+func isInt(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+
+	return false
+}
+
+func isUint(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+func isNumber(k reflect.Kind) bool {
+	return isInt(k) || isUint(k) || k == reflect.Float32 || k == reflect.Float64
+}
@@ -0,0 +1,31 @@
+package component
+
+// Emitter could be embedded in to the component struct to emit custom events,
+// parent component listens to them with @name="HandleX" on the component tag
+type Emitter struct {
+	emit func(name string, payload interface{})
+}
+
+// Emit calls parent handler registered for the event,
+// payload is available to the handler as event Detail
+func (e *Emitter) Emit(name string, payload interface{}) {
+	if e.emit != nil {
+		e.emit(name, payload)
+	}
+}
+
+func (e *Emitter) setEmitHandler(f func(name string, payload interface{})) {
+	e.emit = f
+}
+
+type emitter interface {
+	setEmitHandler(func(name string, payload interface{}))
+}
+
+// SetEmitHandler sets function that receives events emitted by the instance,
+// it does nothing unless component embeds Emitter
+func (w *Wrapper) SetEmitHandler(f func(name string, payload interface{})) {
+	if e, ok := w.instance.(emitter); ok {
+		e.setEmitHandler(f)
+	}
+}
@@ -0,0 +1,66 @@
+package component
+
+// Mounter is implemented by components that need to know
+// when they become a part of the mounted application
+type Mounter interface {
+	Mounted()
+}
+
+// BeforeUpdater is implemented by components that need to run code
+// before their template is refreshed
+type BeforeUpdater interface {
+	BeforeUpdate()
+}
+
+// Updater is implemented by components that need to run code
+// after their template is refreshed
+type Updater interface {
+	Updated()
+}
+
+// BeforeUnmounter is implemented by components that need to run code
+// before they are removed from the application
+type BeforeUnmounter interface {
+	BeforeUnmount()
+}
+
+// Unmounter is implemented by components that need to know
+// when they are removed from the application
+type Unmounter interface {
+	Unmounted()
+}
+
+// Mounted calls Mounted hook of the instance if it has one
+func (w *Wrapper) Mounted() {
+	if h, ok := w.instance.(Mounter); ok {
+		w.track(h.Mounted)
+	}
+}
+
+// BeforeUpdate calls BeforeUpdate hook of the instance if it has one
+func (w *Wrapper) BeforeUpdate() {
+	if h, ok := w.instance.(BeforeUpdater); ok {
+		w.track(h.BeforeUpdate)
+	}
+}
+
+// Updated calls Updated hook of the instance if it has one
+func (w *Wrapper) Updated() {
+	if h, ok := w.instance.(Updater); ok {
+		w.track(h.Updated)
+	}
+}
+
+// BeforeUnmount calls BeforeUnmount hook of the instance if it has one
+func (w *Wrapper) BeforeUnmount() {
+	if h, ok := w.instance.(BeforeUnmounter); ok {
+		w.track(h.BeforeUnmount)
+	}
+}
+
+// Unmounted calls Unmounted hook of the instance if it has one
+func (w *Wrapper) Unmounted() {
+	if h, ok := w.instance.(Unmounter); ok {
+		w.track(h.Unmounted)
+	}
+}
@@ -0,0 +1,32 @@
+package component
+
+import (
+	"strings"
+	"unicode"
+)
+
+// kebab converts Go identifier in to kebab-case attribute name,
+// acronyms are kept together so UserID becomes user-id and HTMLTitle becomes html-title,
+// plural acronyms like URLs and IDs stay a single word
+func kebab(name string) string {
+	runes := []rune(name)
+	var out strings.Builder
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1]) && !plural(runes, i+1)
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				out.WriteRune('-')
+			}
+		}
+		out.WriteRune(unicode.ToLower(r))
+	}
+
+	return out.String()
+}
+
+// plural checks if rune at i is a lower case s ending the word
+func plural(runes []rune, i int) bool {
+	return runes[i] == 's' && (i+1 == len(runes) || !unicode.IsLower(runes[i+1]))
+}
@@ -0,0 +1,143 @@
+package component
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	requiredTag       = "required"
+	defaultTagPrefix  = "default="
+	oneofTagPrefix    = "oneof="
+	validateTagPrefix = "validate="
+	validatePrefix    = "Validate"
+)
+
+// applyPropOptions handles options of the prop tag:
+// required, default=value, oneof=a|b|c and validate=Method,
+// validator is a method set with the tag or a method named Validate<Field>
+// that accepts value of the field and returns an error
+func (w *Wrapper) applyPropOptions(prop string, field reflect.StructField, tags []string) error {
+	checks := make([]func(reflect.Value) error, 0)
+	validator := validatePrefix + field.Name
+	var defaultValue *string
+
+	for _, tag := range tags {
+		switch {
+		case tag == requiredTag:
+			w.required = append(w.required, prop)
+		case strings.HasPrefix(tag, defaultTagPrefix):
+			value := strings.TrimPrefix(tag, defaultTagPrefix)
+			defaultValue = &value
+		case strings.HasPrefix(tag, oneofTagPrefix):
+			checks = append(checks, oneOf(strings.Split(strings.TrimPrefix(tag, oneofTagPrefix), "|")))
+		case strings.HasPrefix(tag, validateTagPrefix):
+			validator = strings.TrimPrefix(tag, validateTagPrefix)
+		}
+	}
+
+	check, err := w.findValidator(field, validator)
+	if err != nil {
+		return err
+	}
+	if check != nil {
+		checks = append(checks, check)
+	}
+
+	if len(checks) > 0 {
+		w.validators[field.Name] = func(v reflect.Value) error {
+			for _, check := range checks {
+				if err := check(v); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	if defaultValue != nil {
+		return w.setDefault(field, *defaultValue)
+	}
+
+	return nil
+}
+
+// setDefault sets default value of the field unless Init has already set it,
+// default value goes through the same validators as the values set by the parent
+func (w *Wrapper) setDefault(field reflect.StructField, value string) error {
+	v, err := convert(value, field.Type)
+	if err != nil {
+		return errors.Wrapf(err, "Invalid default value of field %s", field.Name)
+	}
+
+	err = w.validate(field.Name, v)
+	if err != nil {
+		return errors.Wrapf(err, "Invalid default value of field %s", field.Name)
+	}
+
+	target := reflect.ValueOf(w.instance).Elem().FieldByIndex(field.Index)
+	if target.IsZero() {
+		target.Set(v)
+	}
+
+	return nil
+}
+
+func (w *Wrapper) findValidator(field reflect.StructField, name string) (func(reflect.Value) error, error) {
+	val := reflect.ValueOf(w.instance)
+
+	method, ok := val.Type().MethodByName(name)
+	if !ok {
+		if name != validatePrefix+field.Name {
+			return nil, fmt.Errorf("Could not find validator %s of field %s", name, field.Name)
+		}
+		return nil, nil
+	}
+
+	mt := method.Type
+	if mt.NumIn() != 2 || !field.Type.AssignableTo(mt.In(1)) || mt.NumOut() != 1 || mt.Out(0) != errorType {
+		return nil, fmt.Errorf("Validator %s of field %s should accept value of type %s and return an error", name, field.Name, field.Type)
+	}
+
+	return func(v reflect.Value) error {
+		err, _ := method.Func.Call([]reflect.Value{val, v})[0].Interface().(error)
+		return err
+	}, nil
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func oneOf(options []string) func(reflect.Value) error {
+	return func(v reflect.Value) error {
+		s := fmt.Sprint(v.Interface())
+		for _, option := range options {
+			if s == option {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("Value %q is not one of %s", s, strings.Join(options, "|"))
+	}
+}
+
+// validate checks value against the validators of the field
+func (w *Wrapper) validate(name string, v reflect.Value) error {
+	check, ok := w.validators[name]
+	if !ok {
+		return nil
+	}
+
+	return check(v)
+}
+
+// RequiredProps returns names of the props that have to be set by the parent
+func (w *Wrapper) RequiredProps() []string {
+	props := append([]string{}, w.required...)
+	sort.Strings(props)
+
+	return props
+}
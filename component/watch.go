@@ -0,0 +1,83 @@
+package component
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	watchTagPrefix = "watch="
+	watchPrefix    = "Watch"
+)
+
+// findWatchers collects field watchers, watcher is a method set with wasm:"watch=Method" tag
+// or a method named Watch<Field>, it should accept old and new value of the field
+func (w *Wrapper) findWatchers() error {
+	val := reflect.ValueOf(w.instance)
+	typ := val.Elem().Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := watchPrefix + field.Name
+
+		ts, _ := field.Tag.Lookup(tagKey)
+		for _, tag := range strings.Split(ts, ",") {
+			if strings.HasPrefix(tag, watchTagPrefix) {
+				name = strings.TrimPrefix(tag, watchTagPrefix)
+			}
+		}
+
+		method, ok := val.Type().MethodByName(name)
+		if !ok {
+			if name != watchPrefix+field.Name {
+				return fmt.Errorf("Could not find watcher %s of field %s", name, field.Name)
+			}
+			continue
+		}
+
+		mt := method.Type
+		if mt.NumIn() != 3 || !field.Type.AssignableTo(mt.In(1)) || !field.Type.AssignableTo(mt.In(2)) {
+			return fmt.Errorf("Watcher %s of field %s should accept old and new values of type %s", name, field.Name, field.Type)
+		}
+
+		w.watchers[field.Name] = func(old, new reflect.Value) {
+			method.Func.Call([]reflect.Value{val, old, new})
+		}
+	}
+
+	return nil
+}
+
+// track runs f and calls watchers of the fields that were changed by it,
+// values are compared shallowly so in place changes of slices and maps are not noticed,
+// computed getters are invalidated afterwards
+func (w *Wrapper) track(f func()) {
+	defer func() { w.version++ }()
+
+	if len(w.watchers) == 0 {
+		f()
+		return
+	}
+
+	val := reflect.ValueOf(w.instance).Elem()
+	old := make(map[string]reflect.Value, len(w.watchers))
+	for name := range w.watchers {
+		field := val.FieldByName(name)
+		cpy := reflect.New(field.Type()).Elem()
+		cpy.Set(field)
+		old[name] = cpy
+	}
+
+	f()
+
+	for name, watcher := range w.watchers {
+		current := val.FieldByName(name)
+		if !reflect.DeepEqual(old[name].Interface(), current.Interface()) {
+			watcher(old[name], current)
+		}
+	}
+}
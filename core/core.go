@@ -10,6 +10,7 @@ import (
 	"github.com/Gonzih/wasm-mk2/registry"
 	"github.com/Gonzih/wasm-mk2/scope"
 	"github.com/Gonzih/wasm-mk2/tree"
+	"github.com/Gonzih/wasm-mk2/vdom"
 	"github.com/Gonzih/wasm-mk2/walker"
 	"golang.org/x/net/html"
 )
@@ -29,19 +30,153 @@ func Component(strukt component.ComponentInput, name, templateID string) {
 
 type App struct {
 	Components []tree.Node
+	rendered   []*vdom.VNode
+	target     *vdom.Target
+	backend    dom.DOMHepler
+	pending    bool
+	// dirty holds components which handlers were called since the last update,
+	// everything is refreshed when handler does not belong to any component
+	dirty    []*tree.ComponentNode
+	dirtyAll bool
 }
 
 func New() *App {
 	return &App{}
 }
 
+// Mount walks template with given id and renders result in to the document element with the same id
 func (a *App) Mount(targetID string) error {
-	markup := dom.New().TemplateContent(targetID)
+	backend := dom.Backend()
+	markup := backend.TemplateContent(targetID)
 	r := strings.NewReader(markup)
 	z := html.NewTokenizer(r)
-	p := parser.New(z)
+	p := parser.NewWithID(targetID, z)
 	w := walker.New(p)
+	w.SetNotifier(a.scheduleUpdate)
+	a.backend = backend
 	a.Components = w.WalkAST(scope.Empty())
+	a.rendered = vdom.Snapshot(a.Components)
+	a.target = vdom.NewTarget(backend, backend.MountPoint(targetID))
+	a.target.Render(a.rendered)
+	a.target.Listen(tree.EventNames(a.Components))
+	tree.Mount(a.Components)
+
+	if len(w.Errors()) > 0 {
+		return &MountError{Errors: w.Errors()}
+	}
 
 	return nil
 }
+
+// Update refreshes every component and returns patches
+// describing what has changed since the previous update or mount
+func (a *App) Update() []vdom.Patch {
+	for _, cmp := range a.Components {
+		cmp.Notify()
+	}
+
+	return a.render()
+}
+
+// refresh updates given components together with their descendants,
+// components which ancestors are refreshed as well are skipped
+func (a *App) refresh(nodes []*tree.ComponentNode) []vdom.Patch {
+	dirty := make(map[tree.Node]bool, len(nodes))
+	for _, node := range nodes {
+		dirty[node] = true
+	}
+
+	for _, node := range nodes {
+		if !dirty[node] || hasDirtyAncestor(node, dirty) {
+			continue
+		}
+		dirty[node] = false
+		node.Notify()
+	}
+
+	return a.render()
+}
+
+func hasDirtyAncestor(node tree.Node, dirty map[tree.Node]bool) bool {
+	for n := node.Parent(); n != nil; n = n.Parent() {
+		if _, ok := dirty[n]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// render diffs current state of the tree with the previous one and applies patches to the document
+func (a *App) render() []vdom.Patch {
+	rendered := vdom.Snapshot(a.Components)
+	patches := vdom.Diff(a.rendered, rendered)
+	a.rendered = rendered
+
+	if a.target != nil {
+		a.target.Apply(patches, rendered)
+		a.target.Listen(tree.EventNames(a.Components))
+	}
+
+	return patches
+}
+
+// Unmount calls unmount hooks of every component
+// and removes rendered nodes from the document
+func (a *App) Unmount() {
+	tree.Unmount(a.Components)
+
+	if a.target != nil {
+		a.target.Apply(vdom.Diff(a.rendered, []*vdom.VNode{}), []*vdom.VNode{})
+	}
+
+	a.Components = nil
+	a.rendered = nil
+}
+
+// scheduleUpdate batches updates requested by event handlers
+// in to a single update that runs on the next tick,
+// only components which handlers were called are refreshed then
+func (a *App) scheduleUpdate(node *tree.ComponentNode) {
+	if a.backend == nil {
+		return
+	}
+
+	if node == nil {
+		a.dirtyAll = true
+	} else {
+		a.dirty = append(a.dirty, node)
+	}
+
+	if a.pending {
+		return
+	}
+
+	a.pending = true
+	a.backend.NextTick(func() {
+		dirty, dirtyAll := a.dirty, a.dirtyAll
+		a.pending, a.dirty, a.dirtyAll = false, nil, false
+
+		switch {
+		case a.target == nil:
+		case dirtyAll:
+			a.Update()
+		default:
+			a.refresh(dirty)
+		}
+	})
+}
+
+// MountError holds every error collected while mounting the application
+type MountError struct {
+	Errors []error
+}
+
+func (e *MountError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
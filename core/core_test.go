@@ -3,8 +3,12 @@ package core
 import (
 	"testing"
 
+	"github.com/Gonzih/wasm-mk2/component"
 	"github.com/Gonzih/wasm-mk2/dom"
 	"github.com/Gonzih/wasm-mk2/event"
+	"github.com/Gonzih/wasm-mk2/tree"
+	"github.com/Gonzih/wasm-mk2/vdom"
+	"github.com/Gonzih/wasm-mk2/walker"
 	"github.com/stretchr/testify/require"
 )
 
@@ -50,3 +54,610 @@ func TestBasic(t *testing.T) {
 
 	require.Nil(t, err)
 }
+
+func TestMountReportsParserErrors(t *testing.T) {
+	dom.RegisterMockTemplate("broken-root", "<div>\n<p></div>")
+
+	app := New()
+	err := app.Mount("broken-root")
+
+	require.NotNil(t, err)
+	mountErr, ok := err.(*MountError)
+	require.True(t, ok)
+	require.NotEmpty(t, mountErr.Errors)
+	require.Contains(t, err.Error(), "broken-root:2:4")
+}
+
+func TestUpdate(t *testing.T) {
+	dom.RegisterMockTemplate("app-root", `<mydiv></mydiv>`)
+	dom.RegisterMockTemplate("mydiv-template", `<div :data-id="Counter" @click="HandleClick">{{ Counter > 100 ? "big" : "small" }}</div>`)
+	Component(&MyDiv{}, "mydiv", "mydiv-template")
+
+	app := New()
+	require.Nil(t, app.Mount("app-root"))
+	require.Len(t, app.Update(), 0)
+
+	child := app.Components[0].Children()[0]
+	require.True(t, child.Handle("click", &event.Event{}))
+
+	require.Equal(t, []vdom.Patch{
+		{Op: vdom.SetAttribute, Path: []int{0}, Key: "data-id", Value: "211"},
+		{Op: vdom.SetText, Path: []int{0, 0}, Value: "big"},
+	}, app.Update())
+}
+
+func TestMountRendersInToDocument(t *testing.T) {
+	dom.RegisterMockTemplate("render-root", `<mydiv></mydiv>`)
+	dom.RegisterMockTemplate("mydiv-template", `<p :data-id="Counter" @click="HandleClick">{{ Counter }}</p>`)
+	Component(&MyDiv{}, "mydiv", "mydiv-template")
+
+	app := New()
+	require.Nil(t, app.Mount("render-root"))
+
+	root := dom.New().Document.GetElementByID("render-root")
+	require.Len(t, root.Children, 1)
+	p := root.Children[0]
+	require.Equal(t, "p", p.Tag)
+	require.Equal(t, "11", p.TextContent())
+
+	app.Components[0].Children()[0].Handle("click", &event.Event{})
+	app.Update()
+
+	require.Equal(t, root.Children[0], p)
+	value, _ := p.Attribute("data-id")
+	require.Equal(t, "211", value)
+	require.Equal(t, "211", p.TextContent())
+}
+
+func TestClickThroughDocument(t *testing.T) {
+	dom.RegisterMockTemplate("click-root", `<mydiv></mydiv>`)
+	dom.RegisterMockTemplate("mydiv-template", `<div><button class="inc" @click="HandleClick"><b>+</b></button><span class="count">{{ Counter }}</span></div>`)
+	Component(&MyDiv{}, "mydiv", "mydiv-template")
+
+	app := New()
+	require.Nil(t, app.Mount("click-root"))
+
+	document := dom.New()
+	count, err := document.QuerySelector("#click-root span.count")
+	require.Nil(t, err)
+	require.Equal(t, "11", count.TextContent())
+
+	label, err := document.QuerySelector("#click-root button.inc > b")
+	require.Nil(t, err)
+	label.Click()
+
+	require.Equal(t, "211", count.TextContent())
+
+	root, _ := document.QuerySelector("#click-root")
+	require.Equal(t, `<div id="click-root"><div><button class="inc"><b>+</b></button><span class="count">211</span></div></div>`, root.OuterHTML())
+}
+
+func TestMountReportsWalkerErrors(t *testing.T) {
+	dom.RegisterMockTemplate("typo-root", `<mydiv></mydiv>`)
+	dom.RegisterMockTemplate("mydiv-template", `<div @click="HandleClik">{{ Counter }}</div>`)
+	Component(&MyDiv{}, "mydiv", "mydiv-template")
+
+	app := New()
+	err := app.Mount("typo-root")
+
+	mountErr, ok := err.(*MountError)
+	require.True(t, ok)
+	require.Len(t, mountErr.Errors, 1)
+	_, ok = mountErr.Errors[0].(*walker.UnknownHandlerError)
+	require.True(t, ok)
+	require.Equal(t, "mydiv-template:1:1: unknown handler HandleClik for @click", err.Error())
+	require.Equal(t, "11", dom.New().Document.GetElementByID("typo-root").TextContent())
+}
+
+type Secret struct {
+	Label string `wasm:"state"`
+	token string
+}
+
+func (c *Secret) Init() error {
+	c.Label = "key"
+	c.token = "hidden"
+	return nil
+}
+
+func TestMountReportsUnexportedFields(t *testing.T) {
+	dom.RegisterMockTemplate("secret-root", `<secret></secret>`)
+	dom.RegisterMockTemplate("secret-template", `<p :title="token">{{ Label }} {{ token }}</p>`)
+	Component(&Secret{}, "secret", "secret-template")
+
+	app := New()
+	err := app.Mount("secret-root")
+
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "token")
+	require.NotContains(t, dom.New().Document.GetElementByID("secret-root").TextContent(), "hidden")
+}
+
+var lifecycle []string
+
+type Parent struct {
+	Visible bool `wasm:"state"`
+}
+
+func (c *Parent) Init() error {
+	c.Visible = true
+	return nil
+}
+
+func (c *Parent) HandleToggle(e *event.Event) { c.Visible = !c.Visible }
+func (c *Parent) Mounted()                    { lifecycle = append(lifecycle, "parent mounted") }
+func (c *Parent) BeforeUpdate()               { lifecycle = append(lifecycle, "parent before update") }
+func (c *Parent) Updated()                    { lifecycle = append(lifecycle, "parent updated") }
+func (c *Parent) BeforeUnmount()              { lifecycle = append(lifecycle, "parent before unmount") }
+func (c *Parent) Unmounted()                  { lifecycle = append(lifecycle, "parent unmounted") }
+
+type Child struct{}
+
+func (c *Child) Init() error    { return nil }
+func (c *Child) Mounted()       { lifecycle = append(lifecycle, "child mounted") }
+func (c *Child) BeforeUnmount() { lifecycle = append(lifecycle, "child before unmount") }
+func (c *Child) Unmounted()     { lifecycle = append(lifecycle, "child unmounted") }
+
+func TestLifecycleHooks(t *testing.T) {
+	lifecycle = nil
+	dom.RegisterMockTemplate("lifecycle-root", `<parent></parent>`)
+	dom.RegisterMockTemplate("parent-template", `<div @click="HandleToggle"><child w-if="Visible"></child></div>`)
+	dom.RegisterMockTemplate("child-template", `<p>child</p>`)
+	Component(&Parent{}, "parent", "parent-template")
+	Component(&Child{}, "child", "child-template")
+
+	app := New()
+	require.Nil(t, app.Mount("lifecycle-root"))
+	require.Equal(t, []string{"child mounted", "parent mounted"}, lifecycle)
+
+	lifecycle = nil
+	root := dom.New().Document.GetElementByID("lifecycle-root")
+	root.Children[0].Click()
+	require.Equal(t, []string{"parent before update", "child before unmount", "child unmounted", "parent updated"}, lifecycle)
+	require.Equal(t, "", root.TextContent())
+
+	lifecycle = nil
+	root.Children[0].Click()
+	require.Equal(t, []string{"parent before update", "child mounted", "parent updated"}, lifecycle)
+	require.Equal(t, "child", root.TextContent())
+
+	lifecycle = nil
+	app.Unmount()
+	require.Equal(t, []string{"parent before unmount", "child before unmount", "child unmounted", "parent unmounted"}, lifecycle)
+	require.Len(t, root.Children, 0)
+}
+
+type UserPage struct {
+	Selected string `wasm:"state"`
+}
+
+func (c *UserPage) Init() error {
+	c.Selected = "1"
+	return nil
+}
+
+func (c *UserPage) HandleNext(e *event.Event) { c.Selected += "1" }
+
+type UserCard struct {
+	UserID  string `wasm:"prop"`
+	Fetched []string
+}
+
+func (c *UserCard) Init() error { return nil }
+
+func (c *UserCard) WatchUserID(old, new string) {
+	c.Fetched = append(c.Fetched, new)
+}
+
+func TestWatchLinkedProp(t *testing.T) {
+	dom.RegisterMockTemplate("watch-root", `<user-page></user-page>`)
+	dom.RegisterMockTemplate("user-page-template", `<div @click="HandleNext"><user-card :user-id="Selected"></user-card></div>`)
+	dom.RegisterMockTemplate("user-card-template", `<p>{{ Fetched }}</p>`)
+	Component(&UserPage{}, "user-page", "user-page-template")
+	Component(&UserCard{}, "user-card", "user-card-template")
+
+	app := New()
+	require.Nil(t, app.Mount("watch-root"))
+
+	root := dom.New().Document.GetElementByID("watch-root")
+	require.Equal(t, "[1]", root.TextContent())
+
+	root.Children[0].Click()
+	require.Equal(t, "[1 11]", root.TextContent())
+
+	app.Update()
+	require.Equal(t, "[1 11]", root.TextContent())
+}
+
+type Batched struct {
+	Inner   int `wasm:"state"`
+	Outer   int `wasm:"state"`
+	Updates int
+}
+
+func (c *Batched) Init() error                { return nil }
+func (c *Batched) HandleInner(e *event.Event) { c.Inner++ }
+func (c *Batched) HandleOuter(e *event.Event) { c.Outer++ }
+func (c *Batched) BeforeUpdate()              { c.Updates++ }
+
+func TestHandlersTriggerBatchedUpdate(t *testing.T) {
+	dom.RegisterMockTemplate("batch-root", `<batched></batched>`)
+	dom.RegisterMockTemplate("batched-template", `<div @click="HandleOuter"><button @click="HandleInner">{{ Inner }}</button>{{ Outer }}</div>`)
+	Component(&Batched{}, "batched", "batched-template")
+
+	app := New()
+	require.Nil(t, app.Mount("batch-root"))
+	batched, _ := app.Components[0].(*tree.ComponentNode).Instance.Getter("Updates")
+
+	document := dom.New()
+	button, _ := document.QuerySelector("#batch-root button")
+	button.Click()
+
+	require.Equal(t, "11", document.Document.GetElementByID("batch-root").TextContent())
+	require.Equal(t, 1, batched())
+
+	app.Components[0].Children()[0].Handle("click", &event.Event{})
+	require.Equal(t, "11", document.Document.GetElementByID("batch-root").TextContent())
+
+	document.Flush()
+	require.Equal(t, "12", document.Document.GetElementByID("batch-root").TextContent())
+	require.Equal(t, 2, batched())
+}
+
+type Search struct {
+	Query  string `wasm:"state"`
+	Target string `wasm:"state"`
+}
+
+func (c *Search) Init() error { return nil }
+
+func (c *Search) HandleInput(e *event.Event) {
+	c.Query = e.Value
+	c.Target = e.Target.(*dom.Element).Tag
+	e.StopPropagation()
+}
+
+func (c *Search) HandleOuter(e *event.Event) {
+	c.Query = "should not bubble"
+}
+
+func TestHandlerReceivesEvent(t *testing.T) {
+	dom.RegisterMockTemplate("search-root", `<search></search>`)
+	dom.RegisterMockTemplate("search-template", `<div @input="HandleOuter"><input @input="HandleInput"><p>{{ Target }}: {{ Query }}</p></div>`)
+	Component(&Search{}, "search", "search-template")
+
+	app := New()
+	require.Nil(t, app.Mount("search-root"))
+
+	document := dom.New()
+	input, _ := document.QuerySelector("#search-root input")
+	input.Input("gophers")
+
+	p, _ := document.QuerySelector("#search-root p")
+	require.Equal(t, "input: gophers", p.TextContent())
+}
+
+type Picker struct {
+	component.Emitter
+	Options []string `wasm:"state"`
+}
+
+func (c *Picker) Init() error {
+	c.Options = []string{"go", "rust", "zig"}
+	return nil
+}
+
+func (c *Picker) HandlePick(e *event.Event) {
+	value, _ := e.Target.(*dom.Element).Attribute("data-value")
+	c.Emit("picked", value)
+}
+
+type Form struct {
+	Language string `wasm:"state"`
+	Clicks   int    `wasm:"state"`
+}
+
+func (c *Form) Init() error { return nil }
+
+func (c *Form) HandlePicked(e *event.Event) { c.Language = e.Detail.(string) }
+func (c *Form) HandleClick(e *event.Event)  { c.Clicks++ }
+
+func TestEmitAndDelegation(t *testing.T) {
+	dom.RegisterMockTemplate("emit-root", `<form-cmp></form-cmp>`)
+	dom.RegisterMockTemplate("form-template", `<div><picker @picked="HandlePicked" @click="HandleClick"></picker><p>{{ Language }} {{ Clicks }}</p></div>`)
+	dom.RegisterMockTemplate("picker-template", `<ul @click="HandlePick"><li w-for="option in Options" :key="option" :data-value="option">{{ option }}</li></ul>`)
+	Component(&Form{}, "form-cmp", "form-template")
+	Component(&Picker{}, "picker", "picker-template")
+
+	app := New()
+	require.Nil(t, app.Mount("emit-root"))
+
+	document := dom.New()
+	items, _ := document.QuerySelectorAll("#emit-root li")
+	require.Len(t, items, 3)
+
+	// native clicks inside of the picker do not reach @click of the component tag
+	items[1].Click()
+	p, _ := document.QuerySelector("#emit-root p")
+	require.Equal(t, "rust 0", p.TextContent())
+
+	items[2].Click()
+	require.Equal(t, "zig 0", p.TextContent())
+}
+
+type TextField struct {
+	component.Emitter
+}
+
+func (c *TextField) Init() error { return nil }
+
+func (c *TextField) HandleChange(e *event.Event) { c.Emit("change", e.Value) }
+
+type Profile struct {
+	Changes []interface{}
+}
+
+func (c *Profile) Init() error { return nil }
+
+func (c *Profile) HandleChange(e *event.Event) { c.Changes = append(c.Changes, e.Detail) }
+
+func TestComponentHandlersOnlyReceiveEmittedEvents(t *testing.T) {
+	dom.RegisterMockTemplate("profile-root", `<profile></profile>`)
+	dom.RegisterMockTemplate("profile-template", `<div><text-field @change="HandleChange"></text-field></div>`)
+	dom.RegisterMockTemplate("text-field-template", `<input @change="HandleChange">`)
+	Component(&Profile{}, "profile", "profile-template")
+	Component(&TextField{}, "text-field", "text-field-template")
+
+	app := New()
+	require.Nil(t, app.Mount("profile-root"))
+
+	input, _ := dom.New().QuerySelector("#profile-root input")
+	input.SetAttribute("value", "ada")
+	input.Dispatch("change", &event.Event{})
+
+	getter, _ := app.Components[0].(*tree.ComponentNode).Instance.Getter("Changes")
+	require.Equal(t, []interface{}{"ada"}, getter())
+}
+
+type Settings struct {
+	Name     string  `wasm:"state"`
+	Age      int     `wasm:"state"`
+	Ratio    float64 `wasm:"state"`
+	Admin    bool    `wasm:"state"`
+	Color    string  `wasm:"state"`
+	Size     string  `wasm:"state"`
+	Comment  string  `wasm:"state"`
+	Rendered int
+}
+
+func (c *Settings) Init() error {
+	c.Name = "ada"
+	c.Color = "red"
+	c.Size = "m"
+	return nil
+}
+
+func TestModel(t *testing.T) {
+	dom.RegisterMockTemplate("model-root", `<settings></settings>`)
+	dom.RegisterMockTemplate("settings-template", `<form>
+		<input class="name" w-model="Name">
+		<input class="age" type="number" w-model="Age">
+		<input class="ratio" w-model="Ratio">
+		<input class="admin" type="checkbox" w-model="Admin">
+		<input class="red" type="radio" name="color" value="red" w-model="Color">
+		<input class="blue" type="radio" name="color" value="blue" w-model="Color">
+		<select w-model="Size"><option value="s">S</option><option value="m">M</option></select>
+		<textarea w-model="Comment"></textarea>
+		<p>{{ Name }} {{ Age }} {{ Ratio }} {{ Admin }} {{ Color }} {{ Size }} {{ Comment }}</p>
+	</form>`)
+	Component(&Settings{}, "settings", "settings-template")
+
+	app := New()
+	require.Nil(t, app.Mount("model-root"))
+
+	document := dom.New()
+	query := func(selector string) *dom.Element {
+		el, err := document.QuerySelector("#model-root " + selector)
+		require.Nil(t, err)
+		require.NotNil(t, el, selector)
+		return el
+	}
+
+	value, _ := query(".name").Attribute("value")
+	require.Equal(t, "ada", value)
+	_, checked := query(".red").Attribute("checked")
+	require.True(t, checked)
+	_, checked = query(".admin").Attribute("checked")
+	require.False(t, checked)
+	_, ok := query(".name").Attribute("w-model")
+	require.False(t, ok)
+
+	query(".name").Input("grace")
+	query(".age").Input("36")
+	query(".ratio").Input("0.5")
+	query(".admin").Check(true)
+	query(".blue").Check(true)
+	query("select").Select("s")
+	query("textarea").Input("hi")
+
+	require.Equal(t, "grace 36 0.5 true blue s hi", query("p").TextContent())
+	_, checked = query(".red").Attribute("checked")
+	require.False(t, checked)
+	_, checked = query(".admin").Attribute("checked")
+	require.True(t, checked)
+
+	query(".age").Input("not a number")
+	require.Equal(t, "grace 36 0.5 true blue s hi", query("p").TextContent())
+}
+
+type Ticker struct {
+	_     struct{} `wasm:"computed=Doubled"`
+	Count int      `wasm:"state"`
+}
+
+var ticker *Ticker
+
+func (c *Ticker) Init() error  { return nil }
+func (c *Ticker) Mounted()     { ticker = c }
+func (c *Ticker) Doubled() int { return c.Count * 2 }
+
+func TestUpdateAfterDirectChange(t *testing.T) {
+	dom.RegisterMockTemplate("ticker-root", `<ticker></ticker>`)
+	dom.RegisterMockTemplate("ticker-template", `<p>{{ Doubled }}</p>`)
+	Component(&Ticker{}, "ticker", "ticker-template")
+
+	app := New()
+	require.Nil(t, app.Mount("ticker-root"))
+	app.Update()
+
+	root := dom.New().Document.GetElementByID("ticker-root")
+	require.Equal(t, "0", root.TextContent())
+
+	ticker.Count = 5
+	app.Update()
+	require.Equal(t, "10", root.TextContent())
+}
+
+type Nested struct {
+	Seen []string
+}
+
+func (c *Nested) Init() error { return nil }
+
+func (c *Nested) HandleOuter(e *event.Event) {
+	c.Seen = append(c.Seen, "outer "+e.CurrentTarget.(*dom.Element).Tag)
+}
+
+func (c *Nested) HandleInner(e *event.Event) {
+	c.Seen = append(c.Seen, "inner "+e.CurrentTarget.(*dom.Element).Tag)
+}
+
+func TestHandlersReceiveCurrentTarget(t *testing.T) {
+	dom.RegisterMockTemplate("nested-root", `<nested></nested>`)
+	dom.RegisterMockTemplate("nested-template", `<section @click.capture="HandleOuter"><div @click="HandleOuter"><button @click="HandleInner"><b>+</b></button></div></section>`)
+	Component(&Nested{}, "nested", "nested-template")
+
+	app := New()
+	require.Nil(t, app.Mount("nested-root"))
+
+	label, err := dom.New().QuerySelector("#nested-root b")
+	require.Nil(t, err)
+	label.Click()
+
+	seen, _ := app.Components[0].(*tree.ComponentNode).Instance.Getter("Seen")
+	require.Equal(t, []string{"outer section", "inner button", "outer div"}, seen())
+}
+
+var rows []string
+
+type Rows struct {
+	Items []string `wasm:"state"`
+}
+
+func (c *Rows) Init() error {
+	c.Items = []string{"a", "a", "b"}
+	return nil
+}
+
+func (c *Rows) HandleTrim(e *event.Event) { c.Items = []string{"a"} }
+
+type Row struct {
+	Label string `wasm:"prop"`
+}
+
+func (c *Row) Init() error { return nil }
+func (c *Row) Mounted()    { rows = append(rows, "mounted "+c.Label) }
+func (c *Row) Unmounted()  { rows = append(rows, "unmounted "+c.Label) }
+
+func TestListWithDuplicateKeys(t *testing.T) {
+	rows = nil
+	dom.RegisterMockTemplate("rows-root", `<rows></rows>`)
+	dom.RegisterMockTemplate("rows-template", `<ul @click="HandleTrim"><row w-for="item in Items" :key="item" :label="item"></row></ul>`)
+	dom.RegisterMockTemplate("row-template", `<li>{{ Label }}</li>`)
+	Component(&Rows{}, "rows", "rows-template")
+	Component(&Row{}, "row", "row-template")
+
+	app := New()
+	require.Nil(t, app.Mount("rows-root"))
+	require.Equal(t, []string{"mounted a", "mounted a", "mounted b"}, rows)
+
+	rows = nil
+	app.Update()
+	require.Empty(t, rows)
+
+	root := dom.New().Document.GetElementByID("rows-root")
+	root.Children[0].Click()
+	require.Equal(t, []string{"unmounted a", "unmounted b"}, rows)
+	require.Equal(t, "a", root.TextContent())
+}
+
+type Palette struct {
+	Colors []string `wasm:"state"`
+	Color  string   `wasm:"state"`
+}
+
+func (c *Palette) Init() error {
+	c.Colors = []string{"red", "green"}
+	c.Color = "green"
+	return nil
+}
+
+func TestModelBoundRadioValue(t *testing.T) {
+	dom.RegisterMockTemplate("palette-root", `<palette></palette>`)
+	dom.RegisterMockTemplate("palette-template", `<div><input type="radio" w-for="c in Colors" :key="c" :class="c" :value="c" w-model="Color"><p>{{ Color }}</p></div>`)
+	Component(&Palette{}, "palette", "palette-template")
+
+	app := New()
+	require.Nil(t, app.Mount("palette-root"))
+
+	document := dom.New()
+	red, _ := document.QuerySelector("#palette-root .red")
+	green, _ := document.QuerySelector("#palette-root .green")
+	_, checked := green.Attribute("checked")
+	require.True(t, checked)
+	_, checked = red.Attribute("checked")
+	require.False(t, checked)
+
+	red.Check(true)
+	require.Equal(t, "red", document.Document.GetElementByID("palette-root").TextContent())
+	_, checked = green.Attribute("checked")
+	require.False(t, checked)
+}
+
+type Tally struct {
+	Count   int `wasm:"state"`
+	Updates int
+}
+
+func (c *Tally) Init() error                { return nil }
+func (c *Tally) HandleClick(e *event.Event) { c.Count++ }
+func (c *Tally) BeforeUpdate()              { c.Updates++ }
+
+type TallyLabel struct {
+	Value int `wasm:"prop"`
+}
+
+func (c *TallyLabel) Init() error { return nil }
+
+func TestHandlersRefreshOwnComponent(t *testing.T) {
+	dom.RegisterMockTemplate("tally-root", `<tally></tally><tally></tally>`)
+	dom.RegisterMockTemplate("tally-template", `<button @click="HandleClick">{{ Count }}<tally-label :value="Count"></tally-label></button>`)
+	dom.RegisterMockTemplate("tally-label-template", `<b>{{ Value }}</b>`)
+	Component(&Tally{}, "tally", "tally-template")
+	Component(&TallyLabel{}, "tally-label", "tally-label-template")
+
+	app := New()
+	require.Nil(t, app.Mount("tally-root"))
+	first, _ := app.Components[0].(*tree.ComponentNode).Instance.Getter("Updates")
+	second, _ := app.Components[1].(*tree.ComponentNode).Instance.Getter("Updates")
+
+	root := dom.New().Document.GetElementByID("tally-root")
+	root.Children[0].Click()
+	require.Equal(t, "1100", root.TextContent())
+	require.Equal(t, 1, first())
+	require.Equal(t, 0, second())
+
+	app.Update()
+	require.Equal(t, 2, first())
+	require.Equal(t, 1, second())
+}
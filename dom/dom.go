@@ -1,5 +1,46 @@
 package dom
 
+import (
+	"github.com/Gonzih/wasm-mk2/event"
+)
+
+// Node is a backend specific handle of the document node
+type Node interface{}
+
+// DOMHepler represents document backend the framework renders in to
 type DOMHepler interface {
+	// TemplateContent returns markup of the template with given id
 	TemplateContent(string) string
+	// MountPoint returns emptied container node with given id to render application in to
+	MountPoint(string) Node
+	CreateElement(tag string) Node
+	CreateTextNode(text string) Node
+	SetText(node Node, text string)
+	SetAttribute(node Node, key, value string)
+	RemoveAttribute(node Node, key string)
+	// InsertChild inserts child before the current child at index or appends it to the end
+	InsertChild(parent, child Node, index int)
+	RemoveChild(parent, child Node)
+	AddEventListener(node Node, name string, handler func(*event.Event))
+	// IsSameNode checks if both handles point to the same document node
+	IsSameNode(a, b Node) bool
+	// NextTick calls f once currently running event handlers are done
+	NextTick(f func())
+}
+
+var backend DOMHepler
+
+// SetBackend changes document backend used by the framework
+func SetBackend(b DOMHepler) {
+	backend = b
+}
+
+// Backend returns document backend used by the framework,
+// in memory DOM is used unless some other backend was set
+func Backend() DOMHepler {
+	if backend == nil {
+		return New()
+	}
+
+	return backend
 }
@@ -0,0 +1,225 @@
+package dom
+
+import (
+	"testing"
+
+	"github.com/Gonzih/wasm-mk2/event"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMountPoint(t *testing.T) {
+	d := &DOM{Document: NewElement(DocumentTag)}
+
+	root := d.MountPoint("app").(*Element)
+	require.Equal(t, "div", root.Tag)
+	require.Equal(t, d.Document, root.Parent)
+
+	d.InsertChild(root, d.CreateTextNode("old"), 0)
+	require.Equal(t, root, d.MountPoint("app"))
+	require.Len(t, root.Children, 0)
+	require.Len(t, d.Document.Children, 1)
+}
+
+func TestElementOperations(t *testing.T) {
+	d := &DOM{Document: NewElement(DocumentTag)}
+
+	ul := d.CreateElement("ul").(*Element)
+	a, b, c := d.CreateElement("li"), d.CreateElement("li"), d.CreateTextNode("c")
+
+	d.InsertChild(ul, a, 0)
+	d.InsertChild(ul, c, 5)
+	d.InsertChild(ul, b, 1)
+	require.Equal(t, []*Element{a.(*Element), b.(*Element), c.(*Element)}, ul.Children)
+
+	d.InsertChild(ul, c, 0)
+	require.Equal(t, []*Element{c.(*Element), a.(*Element), b.(*Element)}, ul.Children)
+
+	d.RemoveChild(ul, a)
+	require.Equal(t, []*Element{c.(*Element), b.(*Element)}, ul.Children)
+	require.Nil(t, a.(*Element).Parent)
+
+	d.SetText(c, "changed")
+	require.Equal(t, "changed", ul.TextContent())
+
+	d.SetAttribute(ul, "class", "a")
+	d.SetAttribute(ul, "id", "list")
+	d.SetAttribute(ul, "class", "b")
+	require.Equal(t, []Attribute{{"class", "b"}, {"id", "list"}}, ul.Attrs)
+
+	d.RemoveAttribute(ul, "class")
+	_, ok := ul.Attribute("class")
+	require.False(t, ok)
+
+	d.AddEventListener(ul, "click", func(*event.Event) {})
+	require.Len(t, ul.listeners["click"], 1)
+}
+
+func testDocument() *Element {
+	doc := NewElement(DocumentTag)
+	app := NewElement("div")
+	app.SetAttribute("id", "app")
+	doc.InsertChild(app, 0)
+
+	list := NewElement("ul")
+	list.SetAttribute("class", "list main")
+	app.InsertChild(list, 0)
+
+	for i, text := range []string{"one", "two"} {
+		li := NewElement("li")
+		li.SetAttribute("data-index", string(rune('0'+i)))
+		li.InsertChild(NewText(text), 0)
+		list.InsertChild(li, i)
+	}
+
+	button := NewElement("button")
+	button.SetAttribute("class", "inc")
+	button.SetAttribute("disabled", "")
+	button.InsertChild(NewText("<+>"), 0)
+	app.InsertChild(button, 1)
+
+	return doc
+}
+
+func TestQuerySelector(t *testing.T) {
+	doc := testDocument()
+
+	texts := func(selector string) []string {
+		found, err := doc.QuerySelectorAll(selector)
+		require.Nil(t, err)
+		result := make([]string, 0)
+		for _, el := range found {
+			result = append(result, el.Tag+":"+el.TextContent())
+		}
+		return result
+	}
+
+	require.Equal(t, []string{"li:one", "li:two"}, texts("li"))
+	require.Equal(t, []string{"li:one", "li:two"}, texts("#app li"))
+	require.Equal(t, []string{"li:one", "li:two"}, texts("ul.list.main > li"))
+	require.Equal(t, []string{}, texts("div > li"))
+	require.Equal(t, []string{}, texts("ul.other li"))
+	require.Equal(t, []string{"li:two"}, texts(`li[data-index="1"]`))
+	require.Equal(t, []string{"li:one"}, texts("[data-index=0]"))
+	require.Equal(t, []string{"button:<+>"}, texts("button[disabled]"))
+	require.Equal(t, []string{"li:one", "li:two", "button:<+>"}, texts("li, .inc"))
+	require.Equal(t, []string{"div:onetwo<+>", "ul:onetwo", "li:one", "li:two", "button:<+>"}, texts("*"))
+
+	button, err := doc.QuerySelector("div#app>.inc")
+	require.Nil(t, err)
+	require.Equal(t, "button", button.Tag)
+
+	missing, err := doc.QuerySelector("span")
+	require.Nil(t, err)
+	require.Nil(t, missing)
+
+	for _, invalid := range []string{"", "li,", "> li", "ul >", "li[", "li.", "a > > b"} {
+		_, err := doc.QuerySelectorAll(invalid)
+		require.NotNil(t, err, invalid)
+	}
+
+	_, err = doc.QuerySelectorAll("input[type=checkbox]:checked")
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), `unsupported selector syntax ":checked"`)
+}
+
+func TestOuterHTML(t *testing.T) {
+	doc := testDocument()
+	input := NewElement("input")
+	input.SetAttribute("value", `"quoted"`)
+	doc.Children[0].InsertChild(input, 2)
+
+	require.Equal(t,
+		`<div id="app"><ul class="list main"><li data-index="0">one</li><li data-index="1">two</li></ul>`+
+			`<button class="inc" disabled="">&lt;+&gt;</button><input value="&#34;quoted&#34;"></div>`,
+		doc.OuterHTML(),
+	)
+	require.Equal(t, `<li data-index="0">one</li><li data-index="1">two</li>`, doc.Children[0].Children[0].InnerHTML())
+}
+
+func TestDispatchBubbles(t *testing.T) {
+	doc := testDocument()
+	calls := make([]string, 0)
+	listen := func(el *Element, name string) {
+		el.AddEventListener("click", func(*event.Event) {
+			calls = append(calls, name)
+		})
+	}
+
+	li, _ := doc.QuerySelector("li")
+	listen(li, "li")
+	listen(li.Parent, "ul")
+	listen(doc, "document")
+	li.Parent.AddEventListener("input", func(*event.Event) {
+		calls = append(calls, "input")
+	})
+
+	li.Children[0].Click()
+	require.Equal(t, []string{"li", "ul", "document"}, calls)
+
+	calls = calls[:0]
+	button, _ := doc.QuerySelector(".inc")
+	button.Click()
+	require.Equal(t, []string{"document"}, calls)
+}
+
+func TestNextTick(t *testing.T) {
+	d := &DOM{Document: testDocument()}
+	calls := make([]string, 0)
+
+	button, _ := d.QuerySelector(".inc")
+	button.AddEventListener("click", func(*event.Event) {
+		d.NextTick(func() { calls = append(calls, "tick") })
+		calls = append(calls, "button")
+	})
+	d.Document.AddEventListener("click", func(*event.Event) {
+		calls = append(calls, "document")
+	})
+
+	button.Click()
+	require.Equal(t, []string{"button", "document", "tick"}, calls)
+
+	calls = calls[:0]
+	d.NextTick(func() { calls = append(calls, "flushed") })
+	require.Len(t, calls, 0)
+	d.Flush()
+	require.Equal(t, []string{"flushed"}, calls)
+}
+
+func TestDispatchEvent(t *testing.T) {
+	doc := testDocument()
+	input := NewElement("input")
+	input.SetAttribute("value", "initial")
+	input.SetAttribute("checked", "")
+	doc.Children[0].InsertChild(input, 0)
+
+	var seen []event.Event
+	record := func(e *event.Event) { seen = append(seen, *e) }
+	input.AddEventListener("change", record)
+	doc.Children[0].AddEventListener("change", record)
+
+	require.True(t, input.Dispatch("change", &event.Event{}))
+	require.Len(t, seen, 2)
+	require.Equal(t, "change", seen[0].Type)
+	require.Equal(t, input, seen[0].Target)
+	require.Equal(t, input, seen[0].CurrentTarget)
+	require.Equal(t, doc.Children[0], seen[1].CurrentTarget)
+	require.Equal(t, "initial", seen[0].Value)
+	require.True(t, seen[0].Checked)
+
+	seen = nil
+	require.True(t, input.Input("typed"))
+	require.Len(t, seen, 0)
+	input.AddEventListener("input", func(e *event.Event) {
+		e.PreventDefault()
+		e.StopPropagation()
+		record(e)
+	})
+	doc.AddEventListener("input", record)
+
+	require.False(t, input.Input("typed"))
+	require.Len(t, seen, 1)
+	require.Equal(t, "typed", seen[0].Value)
+	require.True(t, seen[0].DefaultPrevented())
+	value, _ := input.Attribute("value")
+	require.Equal(t, "typed", value)
+}
@@ -1,9 +1,22 @@
 package dom
 
+import (
+	"github.com/Gonzih/wasm-mk2/event"
+)
+
 var dom *DOM
 
+// tasks holds functions queued with NextTick,
+// dispatching counts events that are currently being dispatched
+var (
+	tasks       []func()
+	dispatching int
+)
+
+// DOM is an in memory document backend used in tests and for server side rendering
 type DOM struct {
 	MockTemplates map[string]string
+	Document      *Element
 }
 
 func RegisterMockTemplate(id, content string) {
@@ -14,12 +27,96 @@ func New() *DOM {
 	if dom == nil {
 		dom = &DOM{
 			MockTemplates: make(map[string]string, 0),
+			Document:      NewElement(DocumentTag),
 		}
 	}
 
 	return dom
 }
 
+// QuerySelector returns first element in the document matching selector or nil
+func (d *DOM) QuerySelector(selector string) (*Element, error) {
+	return d.Document.QuerySelector(selector)
+}
+
+// QuerySelectorAll returns all elements in the document matching selector
+func (d *DOM) QuerySelectorAll(selector string) ([]*Element, error) {
+	return d.Document.QuerySelectorAll(selector)
+}
+
 func (d *DOM) TemplateContent(id string) string {
 	return d.MockTemplates[id]
 }
+
+// MountPoint finds element with given id in the document
+// or appends new div with such id to the document
+func (d *DOM) MountPoint(id string) Node {
+	el := d.Document.GetElementByID(id)
+	if el == nil {
+		el = NewElement("div")
+		el.SetAttribute("id", id)
+		d.Document.InsertChild(el, len(d.Document.Children))
+	}
+
+	for len(el.Children) > 0 {
+		el.RemoveChild(el.Children[0])
+	}
+
+	return el
+}
+
+func (d *DOM) CreateElement(tag string) Node {
+	return NewElement(tag)
+}
+
+func (d *DOM) CreateTextNode(text string) Node {
+	return NewText(text)
+}
+
+func (d *DOM) SetText(node Node, text string) {
+	node.(*Element).Text = text
+}
+
+func (d *DOM) SetAttribute(node Node, key, value string) {
+	node.(*Element).SetAttribute(key, value)
+}
+
+func (d *DOM) RemoveAttribute(node Node, key string) {
+	node.(*Element).RemoveAttribute(key)
+}
+
+func (d *DOM) InsertChild(parent, child Node, index int) {
+	parent.(*Element).InsertChild(child.(*Element), index)
+}
+
+func (d *DOM) RemoveChild(parent, child Node) {
+	parent.(*Element).RemoveChild(child.(*Element))
+}
+
+// NextTick queues f until the event that is being dispatched is handled,
+// use Flush to run queued functions outside of event dispatch
+func (d *DOM) NextTick(f func()) {
+	tasks = append(tasks, f)
+}
+
+// Flush runs every queued function including the ones queued while flushing
+func (d *DOM) Flush() {
+	flush()
+}
+
+func flush() {
+	for len(tasks) > 0 {
+		task := tasks[0]
+		tasks = tasks[1:]
+		task()
+	}
+}
+
+func (d *DOM) IsSameNode(a, b Node) bool {
+	el, ok := a.(*Element)
+	return ok && el == b
+}
+
+func (d *DOM) AddEventListener(node Node, name string, handler func(*event.Event)) {
+	node.(*Element).AddEventListener(name, handler)
+}
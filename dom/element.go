@@ -0,0 +1,247 @@
+package dom
+
+import (
+	"html"
+	"strings"
+
+	"github.com/Gonzih/wasm-mk2/ast"
+	"github.com/Gonzih/wasm-mk2/event"
+)
+
+const (
+	// TextTag is used as a tag of text nodes
+	TextTag = "#text"
+	// DocumentTag is used as a tag of the document root
+	DocumentTag = "#document"
+)
+
+// Attribute represents element attribute
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Element represents in memory document node, text nodes use TextTag as a tag
+type Element struct {
+	Tag       string
+	Text      string
+	Attrs     []Attribute
+	Children  []*Element
+	Parent    *Element
+	listeners map[string][]func(*event.Event)
+}
+
+func NewElement(tag string) *Element {
+	return &Element{
+		Tag:       tag,
+		listeners: make(map[string][]func(*event.Event), 0),
+	}
+}
+
+func NewText(text string) *Element {
+	el := NewElement(TextTag)
+	el.Text = text
+
+	return el
+}
+
+func (el *Element) IsText() bool {
+	return el.Tag == TextTag
+}
+
+func (el *Element) Attribute(key string) (string, bool) {
+	for _, attr := range el.Attrs {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+
+	return "", false
+}
+
+func (el *Element) SetAttribute(key, value string) {
+	for i, attr := range el.Attrs {
+		if attr.Key == key {
+			el.Attrs[i].Value = value
+			return
+		}
+	}
+
+	el.Attrs = append(el.Attrs, Attribute{Key: key, Value: value})
+}
+
+func (el *Element) RemoveAttribute(key string) {
+	for i, attr := range el.Attrs {
+		if attr.Key == key {
+			el.Attrs = append(el.Attrs[:i], el.Attrs[i+1:]...)
+			return
+		}
+	}
+}
+
+// InsertChild inserts child before the current child at index or appends it to the end,
+// child is detached from its previous parent first
+func (el *Element) InsertChild(child *Element, index int) {
+	if child.Parent != nil {
+		child.Parent.RemoveChild(child)
+	}
+
+	if index > len(el.Children) || index < 0 {
+		index = len(el.Children)
+	}
+
+	el.Children = append(el.Children, nil)
+	copy(el.Children[index+1:], el.Children[index:])
+	el.Children[index] = child
+	child.Parent = el
+}
+
+func (el *Element) RemoveChild(child *Element) {
+	for i, ch := range el.Children {
+		if ch == child {
+			el.Children = append(el.Children[:i], el.Children[i+1:]...)
+			child.Parent = nil
+			return
+		}
+	}
+}
+
+func (el *Element) AddEventListener(name string, handler func(*event.Event)) {
+	el.listeners[name] = append(el.listeners[name], handler)
+}
+
+// Dispatch calls listeners registered for the event on the element
+// and then on each of its ancestors, just like bubbling event in a browser does,
+// functions queued with NextTick run once outermost dispatch is done.
+// Dispatch returns false when one of the listeners prevented default action.
+func (el *Element) Dispatch(name string, e *event.Event) bool {
+	e.Type = name
+	if e.Target == nil {
+		e.Target = el
+		if value, ok := el.Attribute("value"); ok && e.Value == "" {
+			e.Value = value
+		}
+		if _, ok := el.Attribute("checked"); ok {
+			e.Checked = true
+		}
+	}
+
+	dispatching++
+	for current := el; current != nil && !e.PropagationStopped(); current = current.Parent {
+		e.CurrentTarget = current
+		for _, handler := range current.listeners[name] {
+			handler(e)
+		}
+	}
+	e.CurrentTarget = nil
+	dispatching--
+
+	if dispatching == 0 {
+		flush()
+	}
+
+	return !e.DefaultPrevented()
+}
+
+// Click dispatches click event on the element
+func (el *Element) Click() bool {
+	return el.Dispatch("click", &event.Event{})
+}
+
+// Input sets value attribute of the element and dispatches input event
+func (el *Element) Input(value string) bool {
+	el.SetAttribute("value", value)
+	return el.Dispatch("input", &event.Event{Value: value})
+}
+
+// Check sets or removes checked attribute of the element and dispatches change event
+func (el *Element) Check(checked bool) bool {
+	if checked {
+		el.SetAttribute("checked", "")
+	} else {
+		el.RemoveAttribute("checked")
+	}
+
+	return el.Dispatch("change", &event.Event{})
+}
+
+// Select sets value attribute of the element and dispatches change event
+func (el *Element) Select(value string) bool {
+	el.SetAttribute("value", value)
+	return el.Dispatch("change", &event.Event{Value: value})
+}
+
+// TextContent returns concatenated text of all descendant text nodes
+func (el *Element) TextContent() string {
+	if el.IsText() {
+		return el.Text
+	}
+
+	text := ""
+	for _, ch := range el.Children {
+		text += ch.TextContent()
+	}
+
+	return text
+}
+
+// GetElementByID finds first element with given id among the element and its descendants
+func (el *Element) GetElementByID(id string) *Element {
+	if value, ok := el.Attribute("id"); ok && value == id {
+		return el
+	}
+
+	for _, ch := range el.Children {
+		if found := ch.GetElementByID(id); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// OuterHTML serializes element and its descendants as html,
+// document is serialized as its children markup
+func (el *Element) OuterHTML() string {
+	var out strings.Builder
+	el.writeHTML(&out)
+
+	return out.String()
+}
+
+// InnerHTML serializes element children as html
+func (el *Element) InnerHTML() string {
+	var out strings.Builder
+	for _, ch := range el.Children {
+		ch.writeHTML(&out)
+	}
+
+	return out.String()
+}
+
+func (el *Element) writeHTML(out *strings.Builder) {
+	if el.IsText() {
+		out.WriteString(html.EscapeString(el.Text))
+		return
+	}
+
+	if el.Tag == DocumentTag {
+		out.WriteString(el.InnerHTML())
+		return
+	}
+
+	out.WriteString("<" + el.Tag)
+	for _, attr := range el.Attrs {
+		out.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Value) + `"`)
+	}
+	out.WriteString(">")
+
+	if ast.IsVoidElement(el.Tag) {
+		return
+	}
+
+	for _, ch := range el.Children {
+		ch.writeHTML(out)
+	}
+	out.WriteString("</" + el.Tag + ">")
+}
@@ -0,0 +1,220 @@
+//go:build js && wasm
+// +build js,wasm
+
+package dom
+
+import (
+	"syscall/js"
+
+	"github.com/Gonzih/wasm-mk2/event"
+)
+
+func init() {
+	SetBackend(NewJS())
+}
+
+// JS is a browser document backend
+type JS struct {
+	document js.Value
+}
+
+func NewJS() *JS {
+	return &JS{document: js.Global().Get("document")}
+}
+
+func (d *JS) getElementByID(id string) (js.Value, bool) {
+	el := d.document.Call("getElementById", id)
+	if el.IsNull() || el.IsUndefined() {
+		return el, false
+	}
+
+	return el, true
+}
+
+func (d *JS) TemplateContent(id string) string {
+	el, ok := d.getElementByID(id)
+	if !ok {
+		return ""
+	}
+
+	return el.Get("innerHTML").String()
+}
+
+func (d *JS) MountPoint(id string) Node {
+	el, ok := d.getElementByID(id)
+	if !ok {
+		el = d.document.Call("createElement", "div")
+		el.Call("setAttribute", "id", id)
+		d.document.Get("body").Call("appendChild", el)
+	}
+
+	el.Set("innerHTML", "")
+
+	return el
+}
+
+func (d *JS) CreateElement(tag string) Node {
+	return d.document.Call("createElement", tag)
+}
+
+func (d *JS) CreateTextNode(text string) Node {
+	return d.document.Call("createTextNode", text)
+}
+
+func (d *JS) SetText(node Node, text string) {
+	node.(js.Value).Set("nodeValue", text)
+}
+
+// formProperties lists attributes that only set initial state of the form elements,
+// their properties are kept in sync as well so changes are visible after user input
+var formProperties = map[string]bool{
+	"value":    true,
+	"checked":  true,
+	"selected": true,
+}
+
+func (d *JS) SetAttribute(node Node, key, value string) {
+	el := node.(js.Value)
+	el.Call("setAttribute", key, value)
+
+	if formProperties[key] {
+		if key == "value" {
+			el.Set(key, value)
+		} else {
+			el.Set(key, true)
+		}
+	}
+}
+
+func (d *JS) RemoveAttribute(node Node, key string) {
+	el := node.(js.Value)
+	el.Call("removeAttribute", key)
+
+	if formProperties[key] {
+		if key == "value" {
+			el.Set(key, "")
+		} else {
+			el.Set(key, false)
+		}
+	}
+}
+
+func (d *JS) InsertChild(parent, child Node, index int) {
+	p := parent.(js.Value)
+	children := p.Get("childNodes")
+
+	if index < children.Length() {
+		p.Call("insertBefore", child.(js.Value), children.Index(index))
+		return
+	}
+
+	p.Call("appendChild", child.(js.Value))
+}
+
+func (d *JS) RemoveChild(parent, child Node) {
+	parent.(js.Value).Call("removeChild", child.(js.Value))
+}
+
+func (d *JS) NextTick(f func()) {
+	var cb js.Func
+	cb = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		cb.Release()
+		f()
+		return nil
+	})
+
+	js.Global().Call("setTimeout", cb, 0)
+}
+
+func (d *JS) IsSameNode(a, b Node) bool {
+	first, ok := a.(js.Value)
+	if !ok {
+		return false
+	}
+
+	second, ok := b.(js.Value)
+	return ok && first.Equal(second)
+}
+
+// AddEventListener listens to the event in the capture phase,
+// so listener on the container sees events that do not bubble as well
+func (d *JS) AddEventListener(node Node, name string, handler func(*event.Event)) {
+	f := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		native := args[0]
+		e := newEvent(native)
+		handler(e)
+
+		if e.DefaultPrevented() {
+			native.Call("preventDefault")
+		}
+		if e.PropagationStopped() {
+			native.Call("stopPropagation")
+		}
+
+		return nil
+	})
+
+	node.(js.Value).Call("addEventListener", name, f, true)
+}
+
+// newEvent converts browser event in to the framework event
+func newEvent(native js.Value) *event.Event {
+	target := native.Get("target")
+	e := &event.Event{
+		Type:          stringProperty(native, "type"),
+		Target:        target,
+		CurrentTarget: native.Get("currentTarget"),
+		Value:         stringProperty(target, "value"),
+		Checked:       boolProperty(target, "checked"),
+		Key:           stringProperty(native, "key"),
+		Code:          stringProperty(native, "code"),
+		AltKey:        boolProperty(native, "altKey"),
+		CtrlKey:       boolProperty(native, "ctrlKey"),
+		ShiftKey:      boolProperty(native, "shiftKey"),
+		MetaKey:       boolProperty(native, "metaKey"),
+		ClientX:       intProperty(native, "clientX"),
+		ClientY:       intProperty(native, "clientY"),
+		Native:        native,
+	}
+
+	if detail := native.Get("detail"); !detail.IsUndefined() && !detail.IsNull() {
+		e.Detail = detail
+	}
+
+	return e
+}
+
+func stringProperty(v js.Value, key string) string {
+	if v.Type() != js.TypeObject {
+		return ""
+	}
+
+	p := v.Get(key)
+	if p.Type() != js.TypeString {
+		return ""
+	}
+
+	return p.String()
+}
+
+func boolProperty(v js.Value, key string) bool {
+	if v.Type() != js.TypeObject {
+		return false
+	}
+
+	p := v.Get(key)
+	return p.Type() == js.TypeBoolean && p.Bool()
+}
+
+func intProperty(v js.Value, key string) int {
+	if v.Type() != js.TypeObject {
+		return 0
+	}
+
+	p := v.Get(key)
+	if p.Type() != js.TypeNumber {
+		return 0
+	}
+
+	return p.Int()
+}
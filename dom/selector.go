@@ -0,0 +1,238 @@
+package dom
+
+import (
+	"fmt"
+	"strings"
+)
+
+// selector represents chain of compound selectors joined by combinators,
+// it is matched from right to left
+type selector struct {
+	parts []*compound
+}
+
+type attributeSelector struct {
+	key      string
+	value    string
+	hasValue bool
+}
+
+type compound struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attributeSelector
+	// child marks that compound should match direct parent of the next compound
+	child bool
+}
+
+func (c *compound) matches(el *Element) bool {
+	if el.IsText() || el.Tag == DocumentTag {
+		return false
+	}
+
+	if c.tag != "" && c.tag != "*" && c.tag != el.Tag {
+		return false
+	}
+
+	if c.id != "" {
+		if id, ok := el.Attribute("id"); !ok || id != c.id {
+			return false
+		}
+	}
+
+	if len(c.classes) > 0 {
+		class, _ := el.Attribute("class")
+		classes := strings.Fields(class)
+		for _, want := range c.classes {
+			if !contains(classes, want) {
+				return false
+			}
+		}
+	}
+
+	for _, attr := range c.attrs {
+		value, ok := el.Attribute(attr.key)
+		if !ok || (attr.hasValue && value != attr.value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *selector) matches(el *Element) bool {
+	return s.matchesFrom(el, len(s.parts)-1)
+}
+
+func (s *selector) matchesFrom(el *Element, i int) bool {
+	if !s.parts[i].matches(el) {
+		return false
+	}
+
+	if i == 0 {
+		return true
+	}
+
+	prev := s.parts[i-1]
+	for parent := el.Parent; parent != nil; parent = parent.Parent {
+		if s.matchesFrom(parent, i-1) {
+			return true
+		}
+		if prev.child {
+			return false
+		}
+	}
+
+	return false
+}
+
+// parseSelectors parses comma separated list of selectors,
+// supported are type, universal, id, class and attribute selectors
+// joined with descendant and child combinators
+func parseSelectors(src string) ([]*selector, error) {
+	selectors := make([]*selector, 0)
+
+	for _, group := range strings.Split(src, ",") {
+		sel, err := parseSelector(strings.TrimSpace(group))
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %s", src, err)
+		}
+		selectors = append(selectors, sel)
+	}
+
+	return selectors, nil
+}
+
+func parseSelector(src string) (*selector, error) {
+	if src == "" {
+		return nil, fmt.Errorf("empty selector")
+	}
+
+	sel := &selector{}
+	src = strings.Replace(src, ">", " > ", -1)
+
+	for _, token := range strings.Fields(src) {
+		if token == ">" {
+			if len(sel.parts) == 0 || sel.parts[len(sel.parts)-1].child {
+				return nil, fmt.Errorf("unexpected combinator")
+			}
+			sel.parts[len(sel.parts)-1].child = true
+			continue
+		}
+
+		c, err := parseCompound(token)
+		if err != nil {
+			return nil, err
+		}
+		sel.parts = append(sel.parts, c)
+	}
+
+	if len(sel.parts) == 0 || sel.parts[len(sel.parts)-1].child {
+		return nil, fmt.Errorf("dangling combinator")
+	}
+
+	return sel, nil
+}
+
+func parseCompound(src string) (*compound, error) {
+	c := &compound{}
+	i := 0
+
+	name := func() string {
+		start := i
+		for i < len(src) && !strings.ContainsRune(".#[", rune(src[i])) {
+			i++
+		}
+		return src[start:i]
+	}
+
+	c.tag = strings.ToLower(name())
+
+	for i < len(src) {
+		switch src[i] {
+		case '#':
+			i++
+			c.id = name()
+			if c.id == "" {
+				return nil, fmt.Errorf("empty id")
+			}
+		case '.':
+			i++
+			class := name()
+			if class == "" {
+				return nil, fmt.Errorf("empty class")
+			}
+			c.classes = append(c.classes, class)
+		case '[':
+			end := strings.IndexByte(src[i:], ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed attribute selector")
+			}
+			body := src[i+1 : i+end]
+			i += end + 1
+
+			attr := attributeSelector{key: body}
+			if eq := strings.IndexByte(body, '='); eq != -1 {
+				attr.key = body[:eq]
+				attr.value = strings.Trim(body[eq+1:], `"'`)
+				attr.hasValue = true
+			}
+			if attr.key == "" {
+				return nil, fmt.Errorf("empty attribute name")
+			}
+			c.attrs = append(c.attrs, attr)
+		default:
+			return nil, fmt.Errorf("unsupported selector syntax %q", src[i:])
+		}
+	}
+
+	return c, nil
+}
+
+// QuerySelectorAll returns all descendant elements matching selector in document order
+func (el *Element) QuerySelectorAll(selector string) ([]*Element, error) {
+	selectors, err := parseSelectors(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Element, 0)
+	el.walk(func(candidate *Element) {
+		for _, sel := range selectors {
+			if sel.matches(candidate) {
+				result = append(result, candidate)
+				return
+			}
+		}
+	})
+
+	return result, nil
+}
+
+// QuerySelector returns first descendant element matching selector or nil
+func (el *Element) QuerySelector(selector string) (*Element, error) {
+	all, err := el.QuerySelectorAll(selector)
+	if err != nil || len(all) == 0 {
+		return nil, err
+	}
+
+	return all[0], nil
+}
+
+func (el *Element) walk(f func(*Element)) {
+	for _, ch := range el.Children {
+		f(ch)
+		ch.walk(f)
+	}
+}
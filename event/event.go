@@ -1,4 +1,52 @@
 package event
 
+// Event represents document event passed to the component handlers
 type Event struct {
+	// Type is a name of the event like click or input
+	Type string
+	// Target is a backend node event was dispatched to
+	Target interface{}
+	// CurrentTarget is a backend node which listener is being called
+	CurrentTarget interface{}
+	// Value and Checked are taken from the target form element
+	Value   string
+	Checked bool
+	// Key and Code describe pressed key of the keyboard events
+	Key  string
+	Code string
+
+	AltKey   bool
+	CtrlKey  bool
+	ShiftKey bool
+	MetaKey  bool
+
+	// ClientX and ClientY hold mouse position of the mouse events
+	ClientX int
+	ClientY int
+
+	// Detail holds custom event payload
+	Detail interface{}
+	// Native holds backend specific event object
+	Native interface{}
+
+	defaultPrevented   bool
+	propagationStopped bool
+}
+
+// PreventDefault cancels default action of the event
+func (e *Event) PreventDefault() {
+	e.defaultPrevented = true
+}
+
+func (e *Event) DefaultPrevented() bool {
+	return e.defaultPrevented
+}
+
+// StopPropagation stops event from reaching listeners of the other nodes
+func (e *Event) StopPropagation() {
+	e.propagationStopped = true
+}
+
+func (e *Event) PropagationStopped() bool {
+	return e.propagationStopped
 }
@@ -0,0 +1,150 @@
+// Package expr implements small expression language used by template bindings.
+//
+// Expressions support dotted field paths (User.Name), indexing (Items[0], Labels["en"]),
+// string, number, boolean and nil literals, comparison (== != < <= > >=)
+// and boolean (! && ||) operators, ternaries (Done ? "yes" : "no")
+// and calls to methods without arguments (IsActive(), User.FullName()).
+package expr
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Env resolves identifiers while expression is evaluated
+type Env interface {
+	Getter(name string) (func() interface{}, bool)
+	Method(name string) (func() interface{}, bool)
+}
+
+// TypeEnv resolves identifier types while expression is checked,
+// nil type means that identifier exists but its type is not known in advance
+type TypeEnv interface {
+	FieldType(name string) (reflect.Type, bool)
+	MethodType(name string) (reflect.Type, bool)
+}
+
+// Error represents expression compilation, checking or evaluation error
+type Error struct {
+	Source string
+	Offset int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s in expression %q at offset %d", e.Msg, e.Source, e.Offset)
+}
+
+// Expression represents parsed expression that could be checked and evaluated many times
+type Expression struct {
+	source string
+	root   node
+}
+
+// Compile parses expression source
+func Compile(src string) (*Expression, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{src: src, tokens: tokens}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Expression{source: src, root: root}, nil
+}
+
+// Source returns original expression source
+func (e *Expression) Source() string {
+	return e.source
+}
+
+// Identifier returns name of the field if expression is a single bare identifier
+func (e *Expression) Identifier() (string, bool) {
+	ident, ok := e.root.(*identNode)
+	if !ok {
+		return "", false
+	}
+
+	return ident.name, true
+}
+
+// Check validates expression against types known in the environment
+func (e *Expression) Check(env TypeEnv) error {
+	_, err := e.root.check(env)
+	if err != nil {
+		return e.wrap(err)
+	}
+
+	return nil
+}
+
+// Type checks expression and returns static type of its result,
+// nil type means that result type is only known at runtime
+func (e *Expression) Type(env TypeEnv) (reflect.Type, error) {
+	t, err := e.root.check(env)
+	if err != nil {
+		return nil, e.wrap(err)
+	}
+
+	return t, nil
+}
+
+// Eval evaluates expression in the environment
+func (e *Expression) Eval(env Env) (interface{}, error) {
+	v, err := e.root.eval(env)
+	if err != nil {
+		return nil, e.wrap(err)
+	}
+
+	return v, nil
+}
+
+func (e *Expression) wrap(err error) error {
+	if nodeErr, ok := err.(*nodeError); ok {
+		return &Error{Source: e.source, Offset: nodeErr.offset, Msg: nodeErr.msg}
+	}
+
+	return err
+}
+
+// nodeError is an error produced by the node, it gets source attached by the Expression
+type nodeError struct {
+	offset int
+	msg    string
+}
+
+func (e *nodeError) Error() string {
+	return e.msg
+}
+
+func errorf(offset int, format string, args ...interface{}) error {
+	return &nodeError{offset: offset, msg: fmt.Sprintf(format, args...)}
+}
+
+// Truthy converts any value in to a boolean,
+// zero numbers, empty strings, nils and empty collections are false, structs are always true
+func Truthy(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+
+	if b, ok := v.(bool); ok {
+		return b
+	}
+
+	val := reflect.ValueOf(v)
+	switch val.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String, reflect.Chan:
+		return val.Len() > 0
+	case reflect.Ptr, reflect.Interface, reflect.Func:
+		return !val.IsNil()
+	case reflect.Struct:
+		return true
+	}
+
+	return !val.IsZero()
+}
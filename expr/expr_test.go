@@ -0,0 +1,245 @@
+package expr
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type User struct {
+	Name  string
+	Age   int
+	Admin bool
+	Tags  []string
+}
+
+func (u User) Initials() string {
+	return u.Name[:1]
+}
+
+type Page struct {
+	User    *User
+	Users   []User
+	Labels  map[string]string
+	Count   int
+	Ratio   float64
+	Visible bool
+	Title   string
+	Any     interface{}
+}
+
+func (p *Page) IsEmpty() bool {
+	return len(p.Users) == 0
+}
+
+type testEnv struct {
+	page *Page
+}
+
+func (env *testEnv) Getter(name string) (func() interface{}, bool) {
+	field := reflect.ValueOf(env.page).Elem().FieldByName(name)
+	if !field.IsValid() {
+		return nil, false
+	}
+
+	return func() interface{} { return field.Interface() }, true
+}
+
+func (env *testEnv) Method(name string) (func() interface{}, bool) {
+	method := reflect.ValueOf(env.page).MethodByName(name)
+	if !method.IsValid() {
+		return nil, false
+	}
+
+	return func() interface{} { return method.Call(nil)[0].Interface() }, true
+}
+
+func (env *testEnv) FieldType(name string) (reflect.Type, bool) {
+	field, ok := reflect.TypeOf(env.page).Elem().FieldByName(name)
+	return field.Type, ok
+}
+
+func (env *testEnv) MethodType(name string) (reflect.Type, bool) {
+	method, ok := reflect.TypeOf(env.page).MethodByName(name)
+	if !ok {
+		return nil, false
+	}
+
+	return method.Type.Out(0), true
+}
+
+func newTestEnv() *testEnv {
+	return &testEnv{
+		page: &Page{
+			User:    &User{Name: "Bob", Age: 42, Tags: []string{"a", "b"}},
+			Users:   []User{{Name: "Alice"}, {Name: "Eve", Admin: true}},
+			Labels:  map[string]string{"en": "Hello"},
+			Count:   3,
+			Ratio:   0.5,
+			Visible: true,
+			Title:   "Page",
+		},
+	}
+}
+
+func eval(t *testing.T, src string) interface{} {
+	env := newTestEnv()
+
+	e, err := Compile(src)
+	require.Nil(t, err)
+	require.Nil(t, e.Check(env))
+
+	v, err := e.Eval(env)
+	require.Nil(t, err)
+
+	return v
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected interface{}
+	}{
+		{`Title`, "Page"},
+		{`User.Name`, "Bob"},
+		{`User.Tags[1]`, "b"},
+		{`Users[1].Name`, "Eve"},
+		{`Labels["en"]`, "Hello"},
+		{`Labels.en`, "Hello"},
+		{`Labels['missing']`, ""},
+		{`42`, 42},
+		{`1.5`, 1.5},
+		{`-Count`, -3},
+		{`'single' == "single"`, true},
+		{`!Visible`, false},
+		{`!!Title`, true},
+		{`Count > 2`, true},
+		{`Count <= 2`, false},
+		{`Count == 3.0`, true},
+		{`Ratio < Count`, true},
+		{`User.Age >= 18 && User.Name != "Eve"`, true},
+		{`Visible && Count == 4 || Users[1].Admin`, true},
+		{`User != nil`, true},
+		{`Any == nil`, true},
+		{`Visible ? "shown" : "hidden"`, "shown"},
+		{`Count > 5 ? "many" : Count > 1 ? "few" : "one"`, "few"},
+		{`(Count > 1) == true`, true},
+		{`IsEmpty()`, false},
+		{`User.Initials()`, "B"},
+		{`Users[0].Initials()`, "A"},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, eval(t, tt.src), tt.src)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, src := range []string{
+		`Count >`,
+		`User.`,
+		`Users[0`,
+		`"unterminated`,
+		`Count $ 1`,
+		`Visible ? 1`,
+		`(Count`,
+		`Count Title`,
+		`1()`,
+	} {
+		_, err := Compile(src)
+		require.NotNil(t, err, src)
+		require.IsType(t, &Error{}, err, src)
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		msg string
+	}{
+		{`Missing`, "unknown field Missing"},
+		{`User.Email`, "type expr.User has no field Email"},
+		{`Users["a"]`, "index of type string is not an integer"},
+		{`Count.Name`, "cannot access field Name of type int"},
+		{`Title == 1`, "mismatched types string and int"},
+		{`Visible < 1`, "cannot order values of types bool and int"},
+		{`Missing()`, "unknown method Missing"},
+		{`User.Missing()`, "type *expr.User has no method Missing"},
+		{`-Title`, "cannot negate value of type string"},
+	}
+
+	env := newTestEnv()
+	for _, tt := range tests {
+		e, err := Compile(tt.src)
+		require.Nil(t, err, tt.src)
+
+		err = e.Check(env)
+		require.NotNil(t, err, tt.src)
+		require.Contains(t, err.Error(), tt.msg, tt.src)
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	env := newTestEnv()
+
+	e, err := Compile(`Users[5].Name`)
+	require.Nil(t, err)
+	require.Nil(t, e.Check(env))
+
+	_, err = e.Eval(env)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "index 5 out of range")
+}
+
+func TestIdentifier(t *testing.T) {
+	e, err := Compile(` Count `)
+	require.Nil(t, err)
+
+	name, ok := e.Identifier()
+	require.True(t, ok)
+	require.Equal(t, "Count", name)
+
+	e, err = Compile(`User.Name`)
+	require.Nil(t, err)
+
+	_, ok = e.Identifier()
+	require.False(t, ok)
+}
+
+func TestTruthy(t *testing.T) {
+	require.False(t, Truthy(nil))
+	require.False(t, Truthy(0))
+	require.False(t, Truthy(""))
+	require.False(t, Truthy([]int{}))
+	require.False(t, Truthy((*User)(nil)))
+	require.True(t, Truthy(1))
+	require.True(t, Truthy("a"))
+	require.True(t, Truthy(&User{}))
+	require.True(t, Truthy(User{}))
+}
+
+func TestType(t *testing.T) {
+	env := newTestEnv()
+	tests := map[string]reflect.Type{
+		`Users`:       reflect.TypeOf([]User{}),
+		`User.Name`:   reflect.TypeOf(""),
+		`Count > 1`:   reflect.TypeOf(true),
+		`Labels["a"]`: reflect.TypeOf(""),
+		`Any`:         reflect.TypeOf((*interface{})(nil)).Elem(),
+	}
+
+	for src, expected := range tests {
+		e, err := Compile(src)
+		require.Nil(t, err, src)
+
+		typ, err := e.Type(env)
+		require.Nil(t, err, src)
+		require.Equal(t, expected, typ, src)
+	}
+
+	e, err := Compile(`User.Email`)
+	require.Nil(t, err)
+	_, err = e.Type(env)
+	require.IsType(t, &Error{}, err)
+}
@@ -0,0 +1,97 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenPunct
+)
+
+type token struct {
+	typ    tokenType
+	value  string
+	offset int
+}
+
+func (t token) String() string {
+	if t.typ == tokenEOF {
+		return "end of expression"
+	}
+
+	return fmt.Sprintf("%q", t.value)
+}
+
+// punctuation is ordered so that longer operators are matched first
+var punctuation = []string{
+	"==", "!=", "<=", ">=", "&&", "||",
+	".", "[", "]", "(", ")", "!", "-", "<", ">", "?", ":",
+}
+
+func tokenize(src string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(src)
+	i := 0
+
+	for i < len(runes) {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokenIdent, value: string(runes[start:i]), offset: start})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokenNumber, value: string(runes[start:i]), offset: start})
+		case r == '"' || r == '\'':
+			start := i
+			var out strings.Builder
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				out.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &Error{Source: src, Offset: start, Msg: "unterminated string literal"}
+			}
+			i++
+			tokens = append(tokens, token{typ: tokenString, value: out.String(), offset: start})
+		default:
+			matched := false
+			for _, p := range punctuation {
+				if strings.HasPrefix(string(runes[i:]), p) {
+					tokens = append(tokens, token{typ: tokenPunct, value: p, offset: i})
+					i += len([]rune(p))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, &Error{Source: src, Offset: i, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+		}
+	}
+
+	tokens = append(tokens, token{typ: tokenEOF, offset: len(runes)})
+
+	return tokens, nil
+}
@@ -0,0 +1,431 @@
+package expr
+
+import (
+	"reflect"
+)
+
+var boolType = reflect.TypeOf(true)
+
+type node interface {
+	eval(Env) (interface{}, error)
+	check(TypeEnv) (reflect.Type, error)
+}
+
+type literalNode struct {
+	offset int
+	value  interface{}
+}
+
+func (n *literalNode) eval(Env) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n *literalNode) check(TypeEnv) (reflect.Type, error) {
+	if n.value == nil {
+		return nil, nil
+	}
+
+	return reflect.TypeOf(n.value), nil
+}
+
+type identNode struct {
+	offset int
+	name   string
+}
+
+func (n *identNode) eval(env Env) (interface{}, error) {
+	getter, ok := env.Getter(n.name)
+	if !ok {
+		return nil, errorf(n.offset, "unknown field %s", n.name)
+	}
+
+	return getter(), nil
+}
+
+func (n *identNode) check(env TypeEnv) (reflect.Type, error) {
+	t, ok := env.FieldType(n.name)
+	if !ok {
+		return nil, errorf(n.offset, "unknown field %s", n.name)
+	}
+
+	return t, nil
+}
+
+type fieldNode struct {
+	offset int
+	target node
+	name   string
+}
+
+func (n *fieldNode) eval(env Env) (interface{}, error) {
+	target, err := n.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	val := indirect(reflect.ValueOf(target))
+	if !val.IsValid() {
+		return nil, errorf(n.offset, "cannot access field %s of nil", n.name)
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		field := val.FieldByName(n.name)
+		if !field.IsValid() || !field.CanInterface() {
+			return nil, errorf(n.offset, "type %s has no field %s", val.Type(), n.name)
+		}
+		return field.Interface(), nil
+	case reflect.Map:
+		if val.Type().Key().Kind() == reflect.String {
+			return mapIndex(val, reflect.ValueOf(n.name).Convert(val.Type().Key())), nil
+		}
+	}
+
+	return nil, errorf(n.offset, "cannot access field %s of type %s", n.name, val.Type())
+}
+
+func (n *fieldNode) check(env TypeEnv) (reflect.Type, error) {
+	t, err := n.target.check(env)
+	if err != nil || t == nil {
+		return nil, err
+	}
+
+	t = indirectType(t)
+
+	switch t.Kind() {
+	case reflect.Struct:
+		field, ok := t.FieldByName(n.name)
+		if !ok || field.PkgPath != "" {
+			return nil, errorf(n.offset, "type %s has no field %s", t, n.name)
+		}
+		return field.Type, nil
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String {
+			return t.Elem(), nil
+		}
+	case reflect.Interface:
+		return nil, nil
+	}
+
+	return nil, errorf(n.offset, "cannot access field %s of type %s", n.name, t)
+}
+
+type indexNode struct {
+	offset int
+	target node
+	index  node
+}
+
+func (n *indexNode) eval(env Env) (interface{}, error) {
+	target, err := n.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := n.index.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	val := indirect(reflect.ValueOf(target))
+	if !val.IsValid() {
+		return nil, errorf(n.offset, "cannot index nil")
+	}
+
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array, reflect.String:
+		i, ok := toInt(index)
+		if !ok {
+			return nil, errorf(n.offset, "index %v is not an integer", index)
+		}
+		if i < 0 || i >= val.Len() {
+			return nil, errorf(n.offset, "index %d out of range [0:%d]", i, val.Len())
+		}
+		return val.Index(i).Interface(), nil
+	case reflect.Map:
+		key := reflect.ValueOf(index)
+		if !key.IsValid() || !key.Type().ConvertibleTo(val.Type().Key()) {
+			return nil, errorf(n.offset, "cannot use %v as %s map key", index, val.Type().Key())
+		}
+		return mapIndex(val, key.Convert(val.Type().Key())), nil
+	}
+
+	return nil, errorf(n.offset, "cannot index type %s", val.Type())
+}
+
+func (n *indexNode) check(env TypeEnv) (reflect.Type, error) {
+	t, err := n.target.check(env)
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := n.index.check(env)
+	if err != nil || t == nil {
+		return nil, err
+	}
+
+	t = indirectType(t)
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.String:
+		if it != nil && !isInteger(it.Kind()) {
+			return nil, errorf(n.offset, "index of type %s is not an integer", it)
+		}
+		if t.Kind() == reflect.String {
+			return reflect.TypeOf(byte(0)), nil
+		}
+		return t.Elem(), nil
+	case reflect.Map:
+		if it != nil && !it.ConvertibleTo(t.Key()) {
+			return nil, errorf(n.offset, "cannot use %s as %s map key", it, t.Key())
+		}
+		return t.Elem(), nil
+	case reflect.Interface:
+		return nil, nil
+	}
+
+	return nil, errorf(n.offset, "cannot index type %s", t)
+}
+
+type callNode struct {
+	offset   int
+	receiver node
+	name     string
+}
+
+func (n *callNode) eval(env Env) (interface{}, error) {
+	if n.receiver == nil {
+		method, ok := env.Method(n.name)
+		if !ok {
+			return nil, errorf(n.offset, "unknown method %s", n.name)
+		}
+		return method(), nil
+	}
+
+	receiver, err := n.receiver.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	val := reflect.ValueOf(receiver)
+	if !val.IsValid() {
+		return nil, errorf(n.offset, "cannot call method %s of nil", n.name)
+	}
+
+	method := val.MethodByName(n.name)
+	if !method.IsValid() && val.Kind() != reflect.Ptr {
+		ptr := reflect.New(val.Type())
+		ptr.Elem().Set(val)
+		method = ptr.MethodByName(n.name)
+	}
+
+	if !method.IsValid() {
+		return nil, errorf(n.offset, "type %s has no method %s", val.Type(), n.name)
+	}
+
+	if method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil, errorf(n.offset, "method %s should have no arguments and a single result", n.name)
+	}
+
+	return method.Call(nil)[0].Interface(), nil
+}
+
+func (n *callNode) check(env TypeEnv) (reflect.Type, error) {
+	if n.receiver == nil {
+		t, ok := env.MethodType(n.name)
+		if !ok {
+			return nil, errorf(n.offset, "unknown method %s", n.name)
+		}
+		return t, nil
+	}
+
+	t, err := n.receiver.check(env)
+	if err != nil || t == nil {
+		return nil, err
+	}
+
+	method, ok := t.MethodByName(n.name)
+	if !ok && t.Kind() != reflect.Ptr {
+		method, ok = reflect.PtrTo(t).MethodByName(n.name)
+	}
+
+	if !ok {
+		return nil, errorf(n.offset, "type %s has no method %s", t, n.name)
+	}
+
+	args := method.Type.NumIn()
+	if t.Kind() != reflect.Interface {
+		// method type includes the receiver
+		args--
+	}
+
+	if args != 0 || method.Type.NumOut() != 1 {
+		return nil, errorf(n.offset, "method %s should have no arguments and a single result", n.name)
+	}
+
+	return method.Type.Out(0), nil
+}
+
+type unaryNode struct {
+	offset  int
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(env Env) (interface{}, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "!" {
+		return !Truthy(v), nil
+	}
+
+	val := reflect.ValueOf(v)
+	switch {
+	case val.IsValid() && isInteger(val.Kind()):
+		i, _ := toInt(v)
+		return -i, nil
+	case val.IsValid() && isFloat(val.Kind()):
+		return -val.Float(), nil
+	}
+
+	return nil, errorf(n.offset, "cannot negate %v", v)
+}
+
+func (n *unaryNode) check(env TypeEnv) (reflect.Type, error) {
+	t, err := n.operand.check(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "!" {
+		return boolType, nil
+	}
+
+	if t != nil && !isNumber(t.Kind()) {
+		return nil, errorf(n.offset, "cannot negate value of type %s", t)
+	}
+
+	return t, nil
+}
+
+type binaryNode struct {
+	offset int
+	op     string
+	left   node
+	right  node
+}
+
+func (n *binaryNode) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "&&":
+		if !Truthy(left) {
+			return false, nil
+		}
+		right, err := n.right.eval(env)
+		return Truthy(right), err
+	case "||":
+		if Truthy(left) {
+			return true, nil
+		}
+		right, err := n.right.eval(env)
+		return Truthy(right), err
+	}
+
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	result, ok := compare(n.op, left, right)
+	if !ok {
+		return nil, errorf(n.offset, "cannot compare %v %s %v", left, n.op, right)
+	}
+
+	return result, nil
+}
+
+func (n *binaryNode) check(env TypeEnv) (reflect.Type, error) {
+	left, err := n.left.check(env)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := n.right.check(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "&&" || n.op == "||" || left == nil || right == nil {
+		return boolType, nil
+	}
+
+	lk, rk := indirectType(left).Kind(), indirectType(right).Kind()
+	if lk == reflect.Interface || rk == reflect.Interface {
+		return boolType, nil
+	}
+
+	comparable := (isNumber(lk) && isNumber(rk)) || (lk == reflect.String && rk == reflect.String)
+
+	switch n.op {
+	case "==", "!=":
+		if isNumber(lk) != isNumber(rk) || (lk == reflect.String) != (rk == reflect.String) || (lk == reflect.Bool) != (rk == reflect.Bool) {
+			return nil, errorf(n.offset, "mismatched types %s and %s", left, right)
+		}
+	default:
+		if !comparable {
+			return nil, errorf(n.offset, "cannot order values of types %s and %s", left, right)
+		}
+	}
+
+	return boolType, nil
+}
+
+type ternaryNode struct {
+	offset    int
+	cond      node
+	then      node
+	otherwise node
+}
+
+func (n *ternaryNode) eval(env Env) (interface{}, error) {
+	cond, err := n.cond.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if Truthy(cond) {
+		return n.then.eval(env)
+	}
+
+	return n.otherwise.eval(env)
+}
+
+func (n *ternaryNode) check(env TypeEnv) (reflect.Type, error) {
+	if _, err := n.cond.check(env); err != nil {
+		return nil, err
+	}
+
+	then, err := n.then.check(env)
+	if err != nil {
+		return nil, err
+	}
+
+	otherwise, err := n.otherwise.check(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if then == otherwise {
+		return then, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,234 @@
+package expr
+
+import (
+	"strconv"
+	"strings"
+)
+
+type parser struct {
+	src    string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) parse() (node, error) {
+	n, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().typ != tokenEOF {
+		return nil, p.unexpected()
+	}
+
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) peekPunct(values ...string) bool {
+	t := p.peek()
+	if t.typ != tokenPunct {
+		return false
+	}
+
+	for _, v := range values {
+		if t.value == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *parser) expect(value string) error {
+	if !p.peekPunct(value) {
+		return &Error{Source: p.src, Offset: p.peek().offset, Msg: "expected " + strconv.Quote(value) + ", got " + p.peek().String()}
+	}
+	p.next()
+
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	return &Error{Source: p.src, Offset: p.peek().offset, Msg: "unexpected " + p.peek().String()}
+}
+
+func (p *parser) parseTernary() (node, error) {
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.peekPunct("?") {
+		return cond, nil
+	}
+	offset := p.next().offset
+
+	then, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+
+	otherwise, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ternaryNode{offset: offset, cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *parser) parseComparison() (node, error) {
+	return p.parseBinary(p.parseUnary, "==", "!=", "<", "<=", ">", ">=")
+}
+
+func (p *parser) parseBinary(operand func() (node, error), ops ...string) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peekPunct(ops...) {
+		op := p.next()
+
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{offset: op.offset, op: op.value, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.peekPunct("!", "-") {
+		op := p.next()
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &unaryNode{offset: op.offset, op: op.value, operand: operand}, nil
+	}
+
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.peekPunct("."):
+			p.next()
+			name := p.next()
+			if name.typ != tokenIdent {
+				return nil, &Error{Source: p.src, Offset: name.offset, Msg: "expected field name, got " + name.String()}
+			}
+			n = &fieldNode{offset: name.offset, target: n, name: name.value}
+		case p.peekPunct("["):
+			offset := p.next().offset
+			index, err := p.parseTernary()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = &indexNode{offset: offset, target: n, index: index}
+		case p.peekPunct("("):
+			offset := p.next().offset
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			switch target := n.(type) {
+			case *identNode:
+				n = &callNode{offset: target.offset, name: target.name}
+			case *fieldNode:
+				n = &callNode{offset: target.offset, receiver: target.target, name: target.name}
+			default:
+				return nil, &Error{Source: p.src, Offset: offset, Msg: "only methods can be called"}
+			}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.peek()
+
+	switch t.typ {
+	case tokenIdent:
+		p.next()
+		switch t.value {
+		case "true":
+			return &literalNode{offset: t.offset, value: true}, nil
+		case "false":
+			return &literalNode{offset: t.offset, value: false}, nil
+		case "nil":
+			return &literalNode{offset: t.offset, value: nil}, nil
+		}
+		return &identNode{offset: t.offset, name: t.value}, nil
+	case tokenNumber:
+		p.next()
+		if strings.Contains(t.value, ".") {
+			f, err := strconv.ParseFloat(t.value, 64)
+			if err != nil {
+				return nil, &Error{Source: p.src, Offset: t.offset, Msg: "invalid number " + t.value}
+			}
+			return &literalNode{offset: t.offset, value: f}, nil
+		}
+		i, err := strconv.Atoi(t.value)
+		if err != nil {
+			return nil, &Error{Source: p.src, Offset: t.offset, Msg: "invalid number " + t.value}
+		}
+		return &literalNode{offset: t.offset, value: i}, nil
+	case tokenString:
+		p.next()
+		return &literalNode{offset: t.offset, value: t.value}, nil
+	case tokenPunct:
+		if t.value == "(" {
+			p.next()
+			n, err := p.parseTernary()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return n, nil
+		}
+	}
+
+	return nil, p.unexpected()
+}
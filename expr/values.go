@@ -0,0 +1,154 @@
+package expr
+
+import (
+	"reflect"
+)
+
+func indirect(val reflect.Value) reflect.Value {
+	for val.IsValid() && (val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface) {
+		if val.IsNil() {
+			return reflect.Value{}
+		}
+		val = val.Elem()
+	}
+
+	return val
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func mapIndex(m, key reflect.Value) interface{} {
+	v := m.MapIndex(key)
+	if !v.IsValid() {
+		return reflect.Zero(m.Type().Elem()).Interface()
+	}
+
+	return v.Interface()
+}
+
+func isInteger(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+
+	return false
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func isNumber(k reflect.Kind) bool {
+	return isInteger(k) || isFloat(k)
+}
+
+func toInt(v interface{}) (int, bool) {
+	val := reflect.ValueOf(v)
+	if !val.IsValid() {
+		return 0, false
+	}
+
+	switch {
+	case val.Kind() >= reflect.Int && val.Kind() <= reflect.Int64:
+		return int(val.Int()), true
+	case isInteger(val.Kind()):
+		return int(val.Uint()), true
+	}
+
+	return 0, false
+}
+
+func toFloat(val reflect.Value) float64 {
+	switch {
+	case val.Kind() >= reflect.Int && val.Kind() <= reflect.Int64:
+		return float64(val.Int())
+	case isInteger(val.Kind()):
+		return float64(val.Uint())
+	}
+
+	return val.Float()
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	val := reflect.ValueOf(v)
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return val.IsNil()
+	}
+
+	return false
+}
+
+// compare applies comparison operator to both values,
+// numbers of any kind are compared by value
+func compare(op string, left, right interface{}) (bool, bool) {
+	lv, rv := indirect(reflect.ValueOf(left)), indirect(reflect.ValueOf(right))
+
+	if lv.IsValid() && rv.IsValid() {
+		switch {
+		case isNumber(lv.Kind()) && isNumber(rv.Kind()):
+			return order(op, toFloat(lv), toFloat(rv))
+		case lv.Kind() == reflect.String && rv.Kind() == reflect.String:
+			l, r := lv.String(), rv.String()
+			switch op {
+			case "<":
+				return l < r, true
+			case "<=":
+				return l <= r, true
+			case ">":
+				return l > r, true
+			case ">=":
+				return l >= r, true
+			}
+			return equality(op, l == r)
+		}
+	}
+
+	switch op {
+	case "==", "!=":
+		if isNil(left) || isNil(right) {
+			return equality(op, isNil(left) && isNil(right))
+		}
+		return equality(op, reflect.DeepEqual(left, right))
+	}
+
+	return false, false
+}
+
+func order(op string, l, r float64) (bool, bool) {
+	switch op {
+	case "<":
+		return l < r, true
+	case "<=":
+		return l <= r, true
+	case ">":
+		return l > r, true
+	case ">=":
+		return l >= r, true
+	}
+
+	return equality(op, l == r)
+}
+
+func equality(op string, equal bool) (bool, bool) {
+	switch op {
+	case "==":
+		return equal, true
+	case "!=":
+		return !equal, true
+	}
+
+	return false, false
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/Gonzih/wasm-mk2/ast"
+)
+
+const (
+	directiveFor    = "w-for"
+	directiveIf     = "w-if"
+	directiveElseIf = "w-else-if"
+	directiveElse   = "w-else"
+)
+
+// takeAttribute removes attribute from the element and returns its value
+func takeAttribute(el *ast.Element, name string) (string, bool) {
+	for i, attr := range el.HTMLAttributes {
+		if attr.Name == name {
+			el.HTMLAttributes = append(el.HTMLAttributes[:i:i], el.HTMLAttributes[i+1:]...)
+			return attr.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// groupConditionals replaces sibling elements marked with w-if, w-else-if and w-else
+// with a single ast Conditional node.
+// Element with both w-for and w-if is left as is,
+// its condition is evaluated for every list item.
+func (p *Parser) groupConditionals(nodes []ast.Node) []ast.Node {
+	result := make([]ast.Node, 0, len(nodes))
+	var current *ast.Conditional
+
+	for i, node := range nodes {
+		// whitespace between branches does not break the chain
+		if current != nil && isBlank(node) && i+1 < len(nodes) && isElseBranch(nodes[i+1]) {
+			continue
+		}
+
+		el, ok := node.(*ast.Element)
+		if !ok || hasAttribute(el, directiveFor) {
+			current = nil
+			result = append(result, node)
+			continue
+		}
+
+		if cond, ok := takeAttribute(el, directiveIf); ok {
+			if strings.TrimSpace(cond) == "" {
+				p.errorAt(el.Pos, "<"+el.HTMLTag+">", "%s requires a condition", directiveIf)
+			}
+			current = &ast.Conditional{Pos: el.Pos}
+			current.Branches = append(current.Branches, &ast.ConditionalBranch{Condition: cond, Node: el})
+			result = append(result, current)
+			continue
+		}
+
+		if cond, ok := takeAttribute(el, directiveElseIf); ok {
+			if current == nil {
+				p.errorAt(el.Pos, "<"+el.HTMLTag+">", "%s without preceding %s", directiveElseIf, directiveIf)
+				continue
+			}
+			if strings.TrimSpace(cond) == "" {
+				p.errorAt(el.Pos, "<"+el.HTMLTag+">", "%s requires a condition", directiveElseIf)
+			}
+			current.Branches = append(current.Branches, &ast.ConditionalBranch{Condition: cond, Node: el})
+			continue
+		}
+
+		if _, ok := takeAttribute(el, directiveElse); ok {
+			if current == nil {
+				p.errorAt(el.Pos, "<"+el.HTMLTag+">", "%s without preceding %s", directiveElse, directiveIf)
+				continue
+			}
+			current.Branches = append(current.Branches, &ast.ConditionalBranch{Node: el})
+			current = nil
+			continue
+		}
+
+		current = nil
+		result = append(result, node)
+	}
+
+	return result
+}
+
+func isBlank(node ast.Node) bool {
+	text, ok := node.(*ast.Text)
+	return ok && strings.TrimSpace(text.Content) == ""
+}
+
+func isElseBranch(node ast.Node) bool {
+	el, ok := node.(*ast.Element)
+	return ok && (hasAttribute(el, directiveElseIf) || hasAttribute(el, directiveElse))
+}
+
+func hasAttribute(el *ast.Element, name string) bool {
+	for _, attr := range el.HTMLAttributes {
+		if attr.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,28 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/Gonzih/wasm-mk2/ast"
+)
+
+// Error represents single parsing error with its location in the template
+type Error struct {
+	TemplateID string
+	Pos        ast.Position
+	Token      string
+	Msg        string
+}
+
+func (e *Error) Error() string {
+	location := e.Pos.String()
+	if e.TemplateID != "" {
+		location = fmt.Sprintf("%s:%s", e.TemplateID, location)
+	}
+
+	if e.Token == "" {
+		return fmt.Sprintf("%s: %s", location, e.Msg)
+	}
+
+	return fmt.Sprintf("%s: %s near %q", location, e.Msg, e.Token)
+}
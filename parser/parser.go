@@ -1,21 +1,65 @@
 package parser
 
 import (
+	"fmt"
+	"io"
+	"strings"
+
 	"github.com/Gonzih/wasm-mk2/ast"
 	"golang.org/x/net/html"
 )
 
+// preformattedTags lists elements whose text content is kept verbatim
+var preformattedTags = map[string]bool{
+	"pre":      true,
+	"textarea": true,
+}
+
+// blockTags lists elements that start a new line,
+// whitespace next to them is not rendered and gets dropped
+var blockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "body": true,
+	"dd": true, "details": true, "dialog": true, "div": true, "dl": true, "dt": true,
+	"fieldset": true, "figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"head": true, "header": true, "hr": true, "html": true, "li": true, "link": true,
+	"main": true, "meta": true, "nav": true, "ol": true, "option": true, "p": true,
+	"pre": true, "script": true, "section": true, "style": true, "summary": true,
+	"table": true, "tbody": true, "td": true, "template": true, "tfoot": true, "th": true,
+	"thead": true, "title": true, "tr": true, "ul": true,
+}
+
 // Parser represents our parser state
 type Parser struct {
-	currToken html.Token
-	peekToken html.Token
-	tokenizer *html.Tokenizer
-	errors    []string
+	currToken  html.Token
+	peekToken  html.Token
+	currRaw    string
+	peekRaw    string
+	currPos    ast.Position
+	peekPos    ast.Position
+	pos        ast.Position
+	tokenizer  *html.Tokenizer
+	templateID string
+	errors     []*Error
+	// open holds tags of elements that are currently being parsed
+	open []string
+	// preformatted counts currently open elements that keep whitespace intact
+	preformatted int
 }
 
 // New creates new Parser out of html tokenizer
 func New(z *html.Tokenizer) *Parser {
-	p := &Parser{tokenizer: z}
+	return NewWithID("", z)
+}
+
+// NewWithID creates new Parser for template with given id,
+// id is used only to make error messages more helpful
+func NewWithID(templateID string, z *html.Tokenizer) *Parser {
+	p := &Parser{
+		tokenizer:  z,
+		templateID: templateID,
+		pos:        ast.Position{Line: 1, Column: 1},
+	}
 	p.nextToken()
 	p.nextToken()
 
@@ -23,13 +67,49 @@ func New(z *html.Tokenizer) *Parser {
 }
 
 func (p *Parser) nextToken() {
-	_ = p.tokenizer.Next()
 	p.currToken = p.peekToken
+	p.currRaw = p.peekRaw
+	p.currPos = p.peekPos
+
+	tt := p.tokenizer.Next()
+	p.peekPos = p.pos
+	p.peekRaw = string(p.tokenizer.Raw())
+	p.advance(p.peekRaw)
 	p.peekToken = p.tokenizer.Token()
+
+	if tt == html.ErrorToken && p.tokenizer.Err() != io.EOF {
+		p.errorAt(p.peekPos, "", "%s", p.tokenizer.Err())
+	}
+}
+
+// advance moves current source position past raw token content
+func (p *Parser) advance(raw string) {
+	for _, r := range raw {
+		if r == '\n' {
+			p.pos.Line++
+			p.pos.Column = 1
+		} else {
+			p.pos.Column++
+		}
+	}
+}
+
+func (p *Parser) errorAt(pos ast.Position, token, msg string, args ...interface{}) {
+	p.errors = append(p.errors, &Error{
+		TemplateID: p.templateID,
+		Pos:        pos,
+		Token:      token,
+		Msg:        fmt.Sprintf(msg, args...),
+	})
+}
+
+// TemplateID returns id of the template being parsed
+func (p *Parser) TemplateID() string {
+	return p.templateID
 }
 
 // Errors returns internal parser errors slice
-func (p *Parser) Errors() []string {
+func (p *Parser) Errors() []*Error {
 	return p.errors
 }
 
@@ -38,6 +118,10 @@ func (p *Parser) ParseTree() *ast.Root {
 	root := &ast.Root{}
 
 	for !p.currTokenIs(html.ErrorToken) {
+		if p.currTokenIs(html.EndTagToken) {
+			p.errorAt(p.currPos, p.currRaw, "unexpected end tag </%s>", p.currToken.Data)
+		}
+
 		node := p.parseNode()
 		if node != nil {
 			root.HTMLChildren = append(root.HTMLChildren, node)
@@ -45,6 +129,8 @@ func (p *Parser) ParseTree() *ast.Root {
 		p.nextToken()
 	}
 
+	root.HTMLChildren = p.groupConditionals(trimWhitespace(root.HTMLChildren))
+
 	return root
 }
 
@@ -57,6 +143,10 @@ func (p *Parser) peekTokenIs(tt html.TokenType) bool {
 }
 
 func (p *Parser) parseNode() ast.Node {
+	if p.currTokenIs(html.TextToken) {
+		return p.parseText()
+	}
+
 	if p.currTokenIs(html.StartTagToken) || p.currTokenIs(html.SelfClosingTagToken) {
 		attrs := []ast.Attribute{}
 
@@ -72,22 +162,130 @@ func (p *Parser) parseNode() ast.Node {
 		node := &ast.Element{
 			HTMLTag:        p.currToken.Data,
 			HTMLAttributes: attrs,
+			Pos:            p.currPos,
 		}
+		raw := p.currRaw
 
-		if p.currTokenIs(html.SelfClosingTagToken) {
+		if p.currTokenIs(html.SelfClosingTagToken) || ast.IsVoidElement(node.HTMLTag) {
 			return node
 		}
 
-		for p.peekTokenIs(html.StartTagToken) || p.peekTokenIs(html.SelfClosingTagToken) {
-			p.nextToken()
-			node.HTMLChildren = append(node.HTMLChildren, p.parseNode())
+		p.open = append(p.open, node.HTMLTag)
+		defer func() { p.open = p.open[:len(p.open)-1] }()
+
+		if preformattedTags[node.HTMLTag] {
+			p.preformatted++
+			defer func() { p.preformatted-- }()
 		}
 
-		if p.peekTokenIs(html.EndTagToken) {
-			p.nextToken()
-			return node
+		p.parseChildren(node, raw)
+		if p.preformatted == 0 {
+			node.HTMLChildren = trimWhitespace(node.HTMLChildren)
 		}
+		node.HTMLChildren = p.groupConditionals(node.HTMLChildren)
+
+		return node
 	}
 
 	return nil
 }
+
+// parseChildren parses element children up to the element end tag
+func (p *Parser) parseChildren(node *ast.Element, raw string) {
+	for {
+		switch p.peekToken.Type {
+		case html.StartTagToken, html.SelfClosingTagToken, html.TextToken:
+			p.nextToken()
+			child := p.parseNode()
+			if child != nil {
+				node.HTMLChildren = append(node.HTMLChildren, child)
+			}
+		case html.CommentToken, html.DoctypeToken:
+			p.nextToken()
+		case html.EndTagToken:
+			tag := p.peekToken.Data
+
+			if tag == node.HTMLTag {
+				p.nextToken()
+				return
+			}
+
+			// Just like browsers do, end tag of one of the ancestors
+			// implicitly closes current element and is left for the ancestor,
+			// end tag that does not match anything open is ignored.
+			if p.isOpenAncestor(tag) {
+				p.errorAt(p.peekPos, p.peekRaw, "end tag </%s> implicitly closes <%s>", tag, node.HTMLTag)
+				return
+			}
+
+			p.nextToken()
+			p.errorAt(p.currPos, p.currRaw, "unexpected end tag </%s>", tag)
+		default:
+			p.errorAt(node.Pos, raw, "unclosed element <%s>", node.HTMLTag)
+			return
+		}
+	}
+}
+
+func (p *Parser) isOpenAncestor(tag string) bool {
+	for i := len(p.open) - 2; i >= 0; i-- {
+		if p.open[i] == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseText converts text token in to ast Text node.
+// Whitespace runs are collapsed in to a single space
+// unless we are inside of preformatted element.
+func (p *Parser) parseText() ast.Node {
+	content := p.currToken.Data
+
+	if p.preformatted > 0 {
+		return &ast.Text{Content: content, Pos: p.currPos}
+	}
+
+	return &ast.Text{Content: collapseWhitespace(content), Pos: p.currPos}
+}
+
+// trimWhitespace drops whitespace only text at the start and the end of children
+// and next to block elements, whitespace between inline siblings is kept
+func trimWhitespace(nodes []ast.Node) []ast.Node {
+	result := make([]ast.Node, 0, len(nodes))
+
+	for i, node := range nodes {
+		if isBlank(node) && (i == 0 || i == len(nodes)-1 || isBlock(nodes[i-1]) || isBlock(nodes[i+1])) {
+			continue
+		}
+		result = append(result, node)
+	}
+
+	return result
+}
+
+func isBlock(node ast.Node) bool {
+	el, ok := node.(*ast.Element)
+	return ok && blockTags[el.HTMLTag]
+}
+
+func collapseWhitespace(s string) string {
+	var out strings.Builder
+	space := false
+
+	for _, r := range s {
+		switch r {
+		case ' ', '\t', '\n', '\r', '\f':
+			if !space {
+				out.WriteRune(' ')
+			}
+			space = true
+		default:
+			out.WriteRune(r)
+			space = false
+		}
+	}
+
+	return out.String()
+}
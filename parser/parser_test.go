@@ -150,3 +150,268 @@ func TestParseNestedWithSelfClosingElement(t *testing.T) {
 	require.Equal(t, "img", root.Children()[0].Children()[0].Tag())
 	require.Equal(t, "hr", root.Children()[0].Children()[1].Tag())
 }
+
+func TestParseText(t *testing.T) {
+	s := `<p>Hello</p>`
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	checkParserErrors(t, p)
+
+	require.Len(t, root.Children(), 1)
+	require.Len(t, root.Children()[0].Children(), 1)
+
+	text, ok := root.Children()[0].Children()[0].(*ast.Text)
+	require.True(t, ok)
+	require.Equal(t, "Hello", text.Content)
+}
+
+func TestParseInlineTextWithElements(t *testing.T) {
+	s := `<p>Hello, <b>dear</b>   world!</p>`
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	checkParserErrors(t, p)
+
+	children := root.Children()[0].Children()
+	require.Len(t, children, 3)
+	require.Equal(t, "Hello, ", children[0].(*ast.Text).Content)
+	require.Equal(t, "b", children[1].Tag())
+	require.Equal(t, "dear", children[1].Children()[0].(*ast.Text).Content)
+	require.Equal(t, " world!", children[2].(*ast.Text).Content)
+}
+
+func TestParseDropsWhitespaceBetweenElements(t *testing.T) {
+	s := `
+	<div>
+		<p></p>
+		<a></a>
+	</div>
+	`
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	checkParserErrors(t, p)
+
+	require.Len(t, root.Children(), 1)
+	require.Len(t, root.Children()[0].Children(), 2)
+	require.Equal(t, "p", root.Children()[0].Children()[0].Tag())
+	require.Equal(t, "a", root.Children()[0].Children()[1].Tag())
+}
+
+func TestParseKeepsSpaceBetweenInlineElements(t *testing.T) {
+	s := `<p><b>Hello</b> 	 <i>world</i></p>`
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	checkParserErrors(t, p)
+
+	children := root.Children()[0].Children()
+	require.Len(t, children, 3)
+	require.Equal(t, "b", children[0].Tag())
+	require.Equal(t, " ", children[1].(*ast.Text).Content)
+	require.Equal(t, "i", children[2].Tag())
+}
+
+func TestParseKeepsNewlineBetweenInlineElements(t *testing.T) {
+	s := "<p>\n\t<b>Hello</b>\n\t<i>world</i>\n</p>"
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	checkParserErrors(t, p)
+
+	children := root.Children()[0].Children()
+	require.Len(t, children, 3)
+	require.Equal(t, "b", children[0].Tag())
+	require.Equal(t, " ", children[1].(*ast.Text).Content)
+	require.Equal(t, "i", children[2].Tag())
+}
+
+func TestParseDropsWhitespaceAtEdges(t *testing.T) {
+	s := "<p> <b>Hello</b> </p>"
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	checkParserErrors(t, p)
+
+	children := root.Children()[0].Children()
+	require.Len(t, children, 1)
+	require.Equal(t, "b", children[0].Tag())
+}
+
+func TestParseKeepsPreformattedText(t *testing.T) {
+	s := "<pre>  a\n   b  </pre>"
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	checkParserErrors(t, p)
+
+	require.Equal(t, "  a\n   b  ", root.Children()[0].Children()[0].(*ast.Text).Content)
+}
+
+func TestParseElementPositions(t *testing.T) {
+	s := "<div>\n  <p class=\"a\">Hi</p>\n</div>"
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	checkParserErrors(t, p)
+
+	div := root.Children()[0].(*ast.Element)
+	require.Equal(t, ast.Position{Line: 1, Column: 1}, div.Pos)
+
+	para := div.Children()[0].(*ast.Element)
+	require.Equal(t, ast.Position{Line: 2, Column: 3}, para.Pos)
+
+	text := para.Children()[0].(*ast.Text)
+	require.Equal(t, ast.Position{Line: 2, Column: 16}, text.Pos)
+}
+
+func TestParseUnclosedElementError(t *testing.T) {
+	s := "<div>\n  <span>text"
+	z := html.NewTokenizer(strings.NewReader(s))
+	p := NewWithID("my-template", z)
+	root := p.ParseTree()
+
+	require.Len(t, p.Errors(), 2)
+
+	err := p.Errors()[0]
+	require.Equal(t, "my-template", err.TemplateID)
+	require.Equal(t, ast.Position{Line: 2, Column: 3}, err.Pos)
+	require.Equal(t, "<span>", err.Token)
+	require.Equal(t, `my-template:2:3: unclosed element <span> near "<span>"`, err.Error())
+
+	require.Equal(t, ast.Position{Line: 1, Column: 1}, p.Errors()[1].Pos)
+
+	require.Len(t, root.Children(), 1)
+	require.Equal(t, "span", root.Children()[0].Children()[0].Tag())
+}
+
+func TestParseStrayEndTagError(t *testing.T) {
+	s := `<div></div></p>`
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	require.Len(t, p.Errors(), 1)
+	require.Equal(t, ast.Position{Line: 1, Column: 12}, p.Errors()[0].Pos)
+	require.Equal(t, "</p>", p.Errors()[0].Token)
+	require.Len(t, root.Children(), 1)
+}
+
+func TestParseMismatchedEndTagError(t *testing.T) {
+	s := `<div><span></div>`
+	p := newTestParser(s)
+	p.ParseTree()
+
+	require.Len(t, p.Errors(), 1)
+	require.Equal(t, ast.Position{Line: 1, Column: 12}, p.Errors()[0].Pos)
+	require.Equal(t, "</div>", p.Errors()[0].Token)
+	require.Contains(t, p.Errors()[0].Msg, "implicitly closes <span>")
+}
+
+func TestParseVoidElements(t *testing.T) {
+	s := `<form><label>Name</label><input name="a"><br><img src="x.png"><button>Go</button></form>`
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	checkParserErrors(t, p)
+
+	children := root.Children()[0].Children()
+	require.Len(t, children, 5)
+	require.Equal(t, "label", children[0].Tag())
+	require.Equal(t, "input", children[1].Tag())
+	require.Len(t, children[1].Children(), 0)
+	require.Equal(t, "br", children[2].Tag())
+	require.Equal(t, "img", children[3].Tag())
+	require.Equal(t, "button", children[4].Tag())
+}
+
+func TestParseRecoversFromMisnestedEndTag(t *testing.T) {
+	s := `<div><p><span>a</p><b>b</b></div><i></i>`
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	require.Len(t, p.Errors(), 1)
+
+	require.Len(t, root.Children(), 2)
+	div := root.Children()[0]
+	require.Len(t, div.Children(), 2)
+	require.Equal(t, "p", div.Children()[0].Tag())
+	require.Equal(t, "span", div.Children()[0].Children()[0].Tag())
+	require.Equal(t, "b", div.Children()[1].Tag())
+	require.Equal(t, "i", root.Children()[1].Tag())
+}
+
+func TestParseIgnoresUnmatchedEndTag(t *testing.T) {
+	s := `<div><p>a</span>b</p></div>`
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	require.Len(t, p.Errors(), 1)
+	require.Equal(t, "</span>", p.Errors()[0].Token)
+
+	para := root.Children()[0].Children()[0]
+	require.Len(t, para.Children(), 2)
+	require.Equal(t, "a", para.Children()[0].(*ast.Text).Content)
+	require.Equal(t, "b", para.Children()[1].(*ast.Text).Content)
+}
+
+func TestParseConditionals(t *testing.T) {
+	s := `<div><p w-if="A">a</p><p w-else-if="B">b</p><p w-else>c</p><span w-if="C"></span><i></i></div>`
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	checkParserErrors(t, p)
+
+	children := root.Children()[0].Children()
+	require.Len(t, children, 3)
+
+	cond, ok := children[0].(*ast.Conditional)
+	require.True(t, ok)
+	require.Len(t, cond.Branches, 3)
+	require.Equal(t, "A", cond.Branches[0].Condition)
+	require.Equal(t, "B", cond.Branches[1].Condition)
+	require.Equal(t, "", cond.Branches[2].Condition)
+	require.Len(t, cond.Branches[0].Node.Attributes(), 0)
+	require.Len(t, cond.Branches[2].Node.Attributes(), 0)
+
+	cond, ok = children[1].(*ast.Conditional)
+	require.True(t, ok)
+	require.Len(t, cond.Branches, 1)
+	require.Equal(t, "span", cond.Branches[0].Node.Tag())
+
+	require.Equal(t, "i", children[2].Tag())
+}
+
+func TestParseConditionalsInRoot(t *testing.T) {
+	s := `<p w-if="A"></p> <p w-else></p>`
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	checkParserErrors(t, p)
+
+	require.Len(t, root.Children(), 1)
+	require.IsType(t, &ast.Conditional{}, root.Children()[0])
+}
+
+func TestParseDanglingElseError(t *testing.T) {
+	s := `<div><p w-if="A"></p>text<p w-else></p></div>`
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	require.Len(t, p.Errors(), 1)
+	require.Contains(t, p.Errors()[0].Msg, "w-else without preceding w-if")
+	require.Equal(t, ast.Position{Line: 1, Column: 26}, p.Errors()[0].Pos)
+	require.Len(t, root.Children()[0].Children(), 2)
+}
+
+func TestParseConditionalWithLoopIsNotGrouped(t *testing.T) {
+	s := `<ul><li w-for="item in Items" w-if="item.Visible"></li></ul>`
+	p := newTestParser(s)
+	root := p.ParseTree()
+
+	checkParserErrors(t, p)
+
+	li := root.Children()[0].Children()[0]
+	require.IsType(t, &ast.Element{}, li)
+	require.Len(t, li.Attributes(), 2)
+}
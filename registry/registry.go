@@ -1,7 +1,7 @@
 package registry
 
 import (
-	"log"
+	"fmt"
 
 	"github.com/Gonzih/wasm-mk2/component"
 )
@@ -22,19 +22,15 @@ func Exists(name string) bool {
 	return ok
 }
 
-func Instance(name string) (*component.Wrapper, bool) {
+// Instance creates new instance of the registered component,
+// error is returned when component is not registered or fails to initialize
+func Instance(name string) (*component.Wrapper, error) {
 	w, ok := registry[name]
 	if !ok {
-		return nil, ok
+		return nil, fmt.Errorf("Component %s is not registered", name)
 	}
 
-	instance, err := w.Instance()
-	if err != nil {
-		log.Printf("Error creating instance: %s", err)
-		return nil, false
-	}
-
-	return instance, true
+	return w.Instance()
 }
 
 func TemplateID(name string) (string, bool) {
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/Gonzih/wasm-mk2/component"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -33,8 +34,8 @@ func TestBasicInstance(t *testing.T) {
 
 	Register("MyDiv", w)
 
-	wrapper, ok := Instance("MyDiv")
-	require.True(t, ok)
+	wrapper, err := Instance("MyDiv")
+	require.Nil(t, err)
 
 	getter, ok := wrapper.Getter("Input")
 	require.True(t, ok)
@@ -47,3 +48,20 @@ func TestBasicTemplateID(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, "mydiv-template", id)
 }
+
+type Broken struct{}
+
+func (c *Broken) Init() error { return errors.New("broken") }
+
+func TestInstanceErrors(t *testing.T) {
+	w, err := component.Wasmify(&Broken{})
+	require.Nil(t, err)
+
+	Register("Broken", w)
+
+	_, err = Instance("Broken")
+	require.Equal(t, "broken", errors.Cause(err).Error())
+
+	_, err = Instance("Missing")
+	require.NotNil(t, err)
+}
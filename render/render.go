@@ -0,0 +1,151 @@
+// Package render writes runtime tree as html markup
+package render
+
+import (
+	"io"
+	"strings"
+
+	"github.com/Gonzih/wasm-mk2/ast"
+	"github.com/Gonzih/wasm-mk2/tree"
+	"golang.org/x/net/html"
+)
+
+// rawTextTags lists elements which text content is written without escaping
+var rawTextTags = map[string]bool{
+	"script": true,
+	"style":  true,
+}
+
+type textNode interface {
+	Text() string
+}
+
+type renderer struct {
+	w   io.Writer
+	err error
+	// selected holds value of the select element which options are rendered
+	selected *string
+}
+
+func (r *renderer) write(s string) {
+	if r.err != nil {
+		return
+	}
+
+	_, r.err = io.WriteString(r.w, s)
+}
+
+// HTML writes nodes to the writer as html,
+// components are replaced with their template output
+func HTML(w io.Writer, nodes []tree.Node) error {
+	r := &renderer{w: w}
+	r.nodes(nodes, false)
+
+	return r.err
+}
+
+// String renders nodes in to a string
+func String(nodes []tree.Node) (string, error) {
+	var out strings.Builder
+	err := HTML(&out, nodes)
+
+	return out.String(), err
+}
+
+func (r *renderer) nodes(nodes []tree.Node, raw bool) {
+	for _, node := range nodes {
+		r.node(node, raw)
+	}
+}
+
+func (r *renderer) node(node tree.Node, raw bool) {
+	switch n := node.(type) {
+	case textNode:
+		if raw {
+			r.write(n.Text())
+		} else {
+			r.write(html.EscapeString(n.Text()))
+		}
+	case *tree.HTMLNode:
+		r.element(n)
+	default:
+		r.nodes(node.Children(), raw)
+	}
+}
+
+// element writes html element, value of the form elements is written
+// the way browser reads it from markup: textarea value becomes its content
+// and select value marks matching option as selected
+func (r *renderer) element(n *tree.HTMLNode) {
+	tag := n.Tag()
+	value, hasValue := attribute(n, "value")
+	formValue := hasValue && (tag == "textarea" || tag == "select")
+
+	r.write("<")
+	r.write(tag)
+
+	for _, attr := range n.Props() {
+		if !tree.IsPresent(attr) || (formValue && attr.Key() == "value") {
+			continue
+		}
+		r.write(" ")
+		r.write(attr.Key())
+		r.write(`="`)
+		r.write(html.EscapeString(attr.Value()))
+		r.write(`"`)
+	}
+
+	if tag == "option" && r.selected != nil && optionValue(n) == *r.selected {
+		if _, ok := attribute(n, "selected"); !ok {
+			r.write(` selected=""`)
+		}
+	}
+
+	r.write(">")
+
+	if ast.IsVoidElement(tag) {
+		return
+	}
+
+	switch {
+	case tag == "textarea" && hasValue:
+		r.write(html.EscapeString(value))
+	case tag == "select" && hasValue:
+		selected := r.selected
+		r.selected = &value
+		r.nodes(n.Children(), false)
+		r.selected = selected
+	default:
+		r.nodes(n.Children(), rawTextTags[tag])
+	}
+
+	r.write("</")
+	r.write(tag)
+	r.write(">")
+}
+
+func attribute(n *tree.HTMLNode, key string) (string, bool) {
+	for _, attr := range n.Props() {
+		if attr.Key() == key && tree.IsPresent(attr) {
+			return attr.Value(), true
+		}
+	}
+
+	return "", false
+}
+
+// optionValue returns value attribute of the option or its text when there is none
+func optionValue(n *tree.HTMLNode) string {
+	if value, ok := attribute(n, "value"); ok {
+		return value
+	}
+
+	var text strings.Builder
+	for _, ch := range n.Children() {
+		if t, ok := ch.(textNode); ok {
+			text.WriteString(t.Text())
+		}
+	}
+
+	return strings.TrimSpace(text.String())
+}
@@ -0,0 +1,130 @@
+package render
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Gonzih/wasm-mk2/component"
+	"github.com/Gonzih/wasm-mk2/dom"
+	"github.com/Gonzih/wasm-mk2/event"
+	"github.com/Gonzih/wasm-mk2/registry"
+	"github.com/Gonzih/wasm-mk2/scope"
+	"github.com/Gonzih/wasm-mk2/tree"
+	"github.com/Gonzih/wasm-mk2/walker"
+	"github.com/stretchr/testify/require"
+)
+
+type Item struct {
+	Name string
+}
+
+type ItemList struct {
+	Title string `wasm:"prop"`
+	Items []Item `wasm:"state"`
+	Open  bool   `wasm:"state"`
+}
+
+func (c *ItemList) Init() error {
+	c.Items = []Item{{"<one>"}, {"two & three"}}
+	c.Open = true
+	return nil
+}
+
+func (c *ItemList) HandleToggle(e *event.Event) {
+	c.Open = !c.Open
+}
+
+type Page struct {
+	Heading string `wasm:"state"`
+}
+
+func (c *Page) Init() error {
+	c.Heading = `"Items"`
+	return nil
+}
+
+func walk(t *testing.T, input string) []tree.Node {
+	dom.RegisterMockTemplate("app-root", input)
+	w := walker.NewByID("app-root")
+	nodes := w.WalkAST(scope.Empty())
+	require.Len(t, w.Errors(), 0)
+
+	return nodes
+}
+
+func register(t *testing.T, comp component.ComponentInput, name, template string) {
+	wrapper, err := component.Wasmify(comp)
+	require.Nil(t, err)
+	registry.Register(name, wrapper)
+	registry.RegisterTemplate(name, name+"-template")
+	dom.RegisterMockTemplate(name+"-template", template)
+}
+
+func TestRenderElements(t *testing.T) {
+	nodes := walk(t, `<div class="a&b"><p>Hello, <b>world</b> &amp; <i>you</i> <i>too</i></p><br><input type="text"></div>`)
+
+	out, err := String(nodes)
+	require.Nil(t, err)
+	require.Equal(t, `<div class="a&amp;b"><p>Hello, <b>world</b> &amp; <i>you</i> <i>too</i></p><br><input type="text"></div>`, out)
+}
+
+func TestRenderRawText(t *testing.T) {
+	nodes := walk(t, `<style>p > b { color: red; }</style>`)
+
+	out, err := String(nodes)
+	require.Nil(t, err)
+	require.Equal(t, `<style>p > b { color: red; }</style>`, out)
+}
+
+func TestRenderComponents(t *testing.T) {
+	register(t, &ItemList{}, "item-list", `
+	<section>
+		<h2 @click="HandleToggle">{{ Title }}</h2>
+		<ul w-if="Open">
+			<li w-for="item, i in Items" :key="item.Name" :data-index="i">{{ item.Name }}</li>
+		</ul>
+		<slot></slot>
+	</section>`)
+	register(t, &Page{}, "test-page", `<main><item-list :title="Heading" class="ignored"><p>Footer</p></item-list></main>`)
+
+	nodes := walk(t, `<test-page></test-page>`)
+
+	out, err := String(nodes)
+	require.Nil(t, err)
+	require.Equal(t, `<main><section><h2>&#34;Items&#34;</h2><ul><li data-index="0">&lt;one&gt;</li><li data-index="1">two &amp; three</li></ul><p>Footer</p></section></main>`, out)
+}
+
+type failingWriter struct{}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestRenderWriterError(t *testing.T) {
+	nodes := walk(t, `<div></div>`)
+
+	err := HTML(&failingWriter{}, nodes)
+	require.NotNil(t, err)
+	require.Equal(t, "write failed", err.Error())
+}
+
+type Survey struct {
+	Size    string
+	Comment string
+}
+
+func (c *Survey) Init() error {
+	c.Size = "m"
+	c.Comment = "<hi> & bye"
+	return nil
+}
+
+func TestRenderFormValues(t *testing.T) {
+	register(t, &Survey{}, "survey", `<form><select w-model="Size"><option value="s">S</option><option>m</option></select><textarea w-model="Comment"></textarea></form>`)
+
+	nodes := walk(t, `<survey></survey>`)
+
+	out, err := String(nodes)
+	require.Nil(t, err)
+	require.Equal(t, `<form><select><option value="s">S</option><option selected="">m</option></select><textarea>&lt;hi&gt; &amp; bye</textarea></form>`, out)
+}
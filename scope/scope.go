@@ -1,6 +1,8 @@
 package scope
 
 import (
+	"reflect"
+
 	"github.com/Gonzih/wasm-mk2/component"
 	"github.com/Gonzih/wasm-mk2/event"
 )
@@ -8,6 +10,7 @@ import (
 type Scope struct {
 	Parent  *Scope
 	Wrapper *component.Wrapper
+	vars    map[string]interface{}
 }
 
 func New(w *component.Wrapper, parent *Scope) *Scope {
@@ -18,36 +21,125 @@ func Empty() *Scope {
 	return &Scope{}
 }
 
+// WithVars creates scope layer that holds local variables like w-for loop items,
+// everything else is looked up in the parent scope
+func WithVars(parent *Scope, vars map[string]interface{}) *Scope {
+	s := &Scope{Parent: parent, vars: make(map[string]interface{}, len(vars))}
+	for name, v := range vars {
+		s.vars[name] = v
+	}
+
+	return s
+}
+
+// Set updates local variable value, getters created earlier will see the new value
+func (s *Scope) Set(name string, v interface{}) {
+	if s.vars == nil {
+		s.vars = make(map[string]interface{}, 0)
+	}
+
+	s.vars[name] = v
+}
+
 func (s *Scope) Getter(name string) (func() interface{}, bool) {
-	if s.Wrapper == nil {
-		return nil, false
+	if _, ok := s.vars[name]; ok {
+		return func() interface{} { return s.vars[name] }, true
 	}
 
-	getter, ok := s.Wrapper.Getter(name)
+	if s.Wrapper != nil {
+		if getter, ok := s.Wrapper.Getter(name); ok {
+			return getter, true
+		}
+	}
 
-	if !ok {
-		if s.Parent != nil {
-			getter, ok = s.Parent.Getter(name)
-			return getter, ok
+	if s.Parent != nil {
+		return s.Parent.Getter(name)
+	}
+
+	return nil, false
+}
+
+// Setter looks up setter of the component field,
+// local variables shadow fields and could not be set
+func (s *Scope) Setter(name string) (func(interface{}) error, bool) {
+	if _, ok := s.vars[name]; ok {
+		return nil, false
+	}
+
+	if s.Wrapper != nil {
+		if setter, ok := s.Wrapper.Setter(name); ok {
+			return setter, true
 		}
 	}
 
-	return getter, ok
+	if s.Parent != nil {
+		return s.Parent.Setter(name)
+	}
+
+	return nil, false
 }
 
 func (s *Scope) Handler(name string) (func(*event.Event), bool) {
-	if s.Wrapper == nil {
-		return nil, false
+	if s.Wrapper != nil {
+		if handler, ok := s.Wrapper.Handler(name); ok {
+			return handler, true
+		}
 	}
 
-	handler, ok := s.Wrapper.Handler(name)
+	if s.Parent != nil {
+		return s.Parent.Handler(name)
+	}
+
+	return nil, false
+}
+
+func (s *Scope) Method(name string) (func() interface{}, bool) {
+	if s.Wrapper != nil {
+		if method, ok := s.Wrapper.Method(name); ok {
+			return method, true
+		}
+	}
+
+	if s.Parent != nil {
+		return s.Parent.Method(name)
+	}
+
+	return nil, false
+}
+
+// FieldType returns static type of the field,
+// local variables are typed by their current value
+func (s *Scope) FieldType(name string) (reflect.Type, bool) {
+	if v, ok := s.vars[name]; ok {
+		if v == nil {
+			return nil, true
+		}
+		return reflect.TypeOf(v), true
+	}
 
-	if !ok {
-		if s.Parent != nil {
-			handler, ok = s.Parent.Handler(name)
-			return handler, ok
+	if s.Wrapper != nil {
+		if t, ok := s.Wrapper.FieldType(name); ok {
+			return t, true
 		}
 	}
 
-	return handler, ok
+	if s.Parent != nil {
+		return s.Parent.FieldType(name)
+	}
+
+	return nil, false
+}
+
+func (s *Scope) MethodType(name string) (reflect.Type, bool) {
+	if s.Wrapper != nil {
+		if t, ok := s.Wrapper.MethodType(name); ok {
+			return t, true
+		}
+	}
+
+	if s.Parent != nil {
+		return s.Parent.MethodType(name)
+	}
+
+	return nil, false
 }
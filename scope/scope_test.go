@@ -1,6 +1,7 @@
 package scope
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/Gonzih/wasm-mk2/component"
@@ -72,3 +73,34 @@ func TestRecursiveLookup(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, 1999, getter())
 }
+
+func TestVarsLookup(t *testing.T) {
+	w, err := component.Wasmify(&MyDiv{})
+	require.Nil(t, err)
+	wrapper, err := w.Instance()
+	require.Nil(t, err)
+
+	s := WithVars(New(wrapper, nil), map[string]interface{}{"item": "first", "Input": "shadowed"})
+
+	getter, ok := s.Getter("item")
+	require.True(t, ok)
+	require.Equal(t, "first", getter())
+
+	s.Set("item", 5)
+	require.Equal(t, 5, getter())
+
+	typ, ok := s.FieldType("item")
+	require.True(t, ok)
+	require.Equal(t, reflect.TypeOf(0), typ)
+
+	getter, ok = s.Getter("Input")
+	require.True(t, ok)
+	require.Equal(t, "shadowed", getter())
+
+	getter, ok = s.Getter("Counter")
+	require.True(t, ok)
+	require.Equal(t, 11, getter())
+
+	_, ok = s.Getter("Missing")
+	require.False(t, ok)
+}
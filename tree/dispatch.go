@@ -0,0 +1,91 @@
+package tree
+
+import (
+	"github.com/Gonzih/wasm-mk2/event"
+)
+
+// handlerOwner is implemented by nodes that have event handlers
+type handlerOwner interface {
+	Handlers() []*Handler
+}
+
+// Link sets parent of every node and links their children recursively,
+// component body is linked through the slots it is placed in
+func Link(nodes []Node, parent Node) {
+	for _, node := range nodes {
+		node.SetParent(parent)
+		Link(node.Children(), node)
+	}
+}
+
+// Dispatch delivers event to the target node and its ancestors just like browser does,
+// capture handlers are called from the root down to the target
+// and then the rest of handlers from the target up to the root.
+// Dispatch stops once one of the handlers stops event propagation.
+// Handlers of component tags are skipped, they only receive events emitted by the component.
+// Element returns backend node of the tree node, it is set as event current target
+// while handlers of that node are called, nil element leaves current target as is.
+func Dispatch(target Node, name string, e *event.Event, element func(Node) interface{}) {
+	current := e.CurrentTarget
+	defer func() { e.CurrentTarget = current }()
+
+	path := make([]Node, 0)
+	for node := target; node != nil; node = node.Parent() {
+		path = append(path, node)
+	}
+
+	for i := len(path) - 1; i >= 0; i-- {
+		if e.PropagationStopped() {
+			return
+		}
+		handle(path[i], name, e, true, element)
+	}
+
+	for _, node := range path {
+		if e.PropagationStopped() {
+			return
+		}
+		handle(node, name, e, false, element)
+	}
+}
+
+func handle(node Node, name string, e *event.Event, capture bool, element func(Node) interface{}) {
+	owner, ok := node.(handlerOwner)
+	if _, emitOnly := node.(*ComponentNode); !ok || emitOnly {
+		return
+	}
+
+	if element != nil {
+		e.CurrentTarget = element(node)
+	}
+
+	for _, handler := range owner.Handlers() {
+		if handler.Key == name && handler.Capture == capture {
+			handler.F(e)
+		}
+	}
+}
+
+// EventNames returns names of the events element nodes and their descendants have handlers for
+func EventNames(nodes []Node) []string {
+	seen := make(map[string]bool, 0)
+	names := make([]string, 0)
+
+	var collect func([]Node)
+	collect = func(nodes []Node) {
+		for _, node := range nodes {
+			if owner, ok := node.(*HTMLNode); ok {
+				for _, handler := range owner.Handlers() {
+					if !seen[handler.Key] {
+						seen[handler.Key] = true
+						names = append(names, handler.Key)
+					}
+				}
+			}
+			collect(node.Children())
+		}
+	}
+	collect(nodes)
+
+	return names
+}
@@ -0,0 +1,83 @@
+package tree
+
+// mountable is implemented by nodes that track
+// whether they are a part of the mounted tree
+type mountable interface {
+	mount()
+	unmount()
+}
+
+// Mount marks nodes as a part of the mounted tree,
+// components get their Mounted hook called after all of their children are mounted
+func Mount(nodes []Node) {
+	for _, node := range nodes {
+		if m, ok := node.(mountable); ok {
+			m.mount()
+		} else {
+			Mount(node.Children())
+		}
+	}
+}
+
+// Unmount removes nodes from the mounted tree,
+// components get BeforeUnmount hook called before their children are unmounted
+// and Unmounted hook called after that
+func Unmount(nodes []Node) {
+	for _, node := range nodes {
+		if m, ok := node.(mountable); ok {
+			m.unmount()
+		} else {
+			Unmount(node.Children())
+		}
+	}
+}
+
+func (n *ComponentNode) mount() {
+	if n.mounted {
+		return
+	}
+
+	Mount(n.NodeChildren)
+	n.mounted = true
+
+	if n.Instance != nil {
+		n.Instance.Mounted()
+	}
+}
+
+func (n *ComponentNode) unmount() {
+	if !n.mounted {
+		return
+	}
+
+	if n.Instance != nil {
+		n.Instance.BeforeUnmount()
+	}
+
+	Unmount(n.NodeChildren)
+	n.mounted = false
+
+	if n.Instance != nil {
+		n.Instance.Unmounted()
+	}
+}
+
+func (n *ConditionalNode) mount() {
+	n.mounted = true
+	Mount(n.NodeChildren)
+}
+
+func (n *ConditionalNode) unmount() {
+	Unmount(n.NodeChildren)
+	n.mounted = false
+}
+
+func (n *ListNode) mount() {
+	n.mounted = true
+	Mount(n.NodeChildren)
+}
+
+func (n *ListNode) unmount() {
+	Unmount(n.NodeChildren)
+	n.mounted = false
+}
@@ -1,6 +1,8 @@
 package tree
 
 import (
+	"log"
+
 	"github.com/Gonzih/wasm-mk2/component"
 	"github.com/Gonzih/wasm-mk2/event"
 )
@@ -13,6 +15,8 @@ type Node interface {
 	Refresh()
 	Notify()
 	Handle(string, *event.Event) bool
+	Parent() Node
+	SetParent(Node)
 }
 
 type Attribute interface {
@@ -49,23 +53,55 @@ func (p *LinkedAttribute) Key() string   { return p.K }
 func (p *LinkedAttribute) Value() string { return p.F() }
 func (p *LinkedAttribute) Refresh()      { p.Sync() }
 
+// BooleanAttribute is present only while F returns true, like checked or disabled
+type BooleanAttribute struct {
+	K string
+	F func() bool
+}
+
+func (p *BooleanAttribute) Key() string   { return p.K }
+func (p *BooleanAttribute) Value() string { return "" }
+func (p *BooleanAttribute) Refresh()      {}
+func (p *BooleanAttribute) Present() bool { return p.F() }
+
+// IsPresent checks if attribute should be rendered
+func IsPresent(attr Attribute) bool {
+	optional, ok := attr.(interface{ Present() bool })
+	return !ok || optional.Present()
+}
+
 type HTMLNode struct {
 	NodeHandlers []*Handler
 	NodeTag      string
 	NodeChildren []Node
 	NodeProps    []Attribute
+	parent       Node
 }
 
-func (n *HTMLNode) Tag() string        { return n.NodeTag }
-func (n *HTMLNode) Children() []Node   { return n.NodeChildren }
-func (n *HTMLNode) Body() []Node       { return []Node{} }
-func (n *HTMLNode) Props() []Attribute { return n.NodeProps }
-func (n *HTMLNode) Refresh()           {}
-func (n *HTMLNode) Notify()            {}
+func (n *HTMLNode) Tag() string          { return n.NodeTag }
+func (n *HTMLNode) Parent() Node         { return n.parent }
+func (n *HTMLNode) SetParent(p Node)     { n.parent = p }
+func (n *HTMLNode) Children() []Node     { return n.NodeChildren }
+func (n *HTMLNode) Body() []Node         { return []Node{} }
+func (n *HTMLNode) Props() []Attribute   { return n.NodeProps }
+func (n *HTMLNode) Handlers() []*Handler { return n.NodeHandlers }
+
+func (n *HTMLNode) Notify() {
+	for _, sub := range n.NodeChildren {
+		sub.Refresh()
+	}
+}
+
+func (n *HTMLNode) Refresh() {
+	for _, prop := range n.NodeProps {
+		prop.Refresh()
+	}
+	n.Notify()
+}
 
 func (n *HTMLNode) Handle(name string, e *event.Event) bool {
 	for _, handle := range n.NodeHandlers {
-		if handle.Key == name {
+		if handle.Key == name && !handle.Capture {
 			handle.F(e)
 			return true
 		}
@@ -74,6 +110,227 @@ func (n *HTMLNode) Handle(name string, e *event.Event) bool {
 	return false
 }
 
+type TextNode struct {
+	NodeText string
+	parent   Node
+}
+
+func (n *TextNode) Tag() string                      { return "#text" }
+func (n *TextNode) Parent() Node                     { return n.parent }
+func (n *TextNode) SetParent(p Node)                 { n.parent = p }
+func (n *TextNode) Text() string                     { return n.NodeText }
+func (n *TextNode) Children() []Node                 { return []Node{} }
+func (n *TextNode) Body() []Node                     { return []Node{} }
+func (n *TextNode) Props() []Attribute               { return []Attribute{} }
+func (n *TextNode) Refresh()                         {}
+func (n *TextNode) Notify()                          {}
+func (n *TextNode) Handle(string, *event.Event) bool { return false }
+
+type DynamicTextNode struct {
+	F      func() string
+	parent Node
+}
+
+func (n *DynamicTextNode) Tag() string                      { return "#text" }
+func (n *DynamicTextNode) Parent() Node                     { return n.parent }
+func (n *DynamicTextNode) SetParent(p Node)                 { n.parent = p }
+func (n *DynamicTextNode) Text() string                     { return n.F() }
+func (n *DynamicTextNode) Children() []Node                 { return []Node{} }
+func (n *DynamicTextNode) Body() []Node                     { return []Node{} }
+func (n *DynamicTextNode) Props() []Attribute               { return []Attribute{} }
+func (n *DynamicTextNode) Refresh()                         {}
+func (n *DynamicTextNode) Notify()                          {}
+func (n *DynamicTextNode) Handle(string, *event.Event) bool { return false }
+
+type ConditionalBranch struct {
+	Condition func() bool
+	Build     func() []Node
+}
+
+// ConditionalNode renders children of the first branch with truthy condition,
+// branch without condition is always truthy.
+// Children of the branch are built every time branch gets activated
+// and dropped once it is switched off, mounted children get unmounted then.
+type ConditionalNode struct {
+	Branches     []*ConditionalBranch
+	NodeChildren []Node
+	active       *ConditionalBranch
+	mounted      bool
+	parent       Node
+}
+
+func (n *ConditionalNode) Tag() string                      { return "#if" }
+func (n *ConditionalNode) Parent() Node                     { return n.parent }
+func (n *ConditionalNode) SetParent(p Node)                 { n.parent = p }
+func (n *ConditionalNode) Children() []Node                 { return n.NodeChildren }
+func (n *ConditionalNode) Body() []Node                     { return []Node{} }
+func (n *ConditionalNode) Props() []Attribute               { return []Attribute{} }
+func (n *ConditionalNode) Refresh()                         { n.Notify() }
+func (n *ConditionalNode) Handle(string, *event.Event) bool { return false }
+
+func (n *ConditionalNode) Notify() {
+	var active *ConditionalBranch
+	for _, branch := range n.Branches {
+		if branch.Condition == nil || branch.Condition() {
+			active = branch
+			break
+		}
+	}
+
+	built := false
+	if active != n.active {
+		if n.mounted {
+			Unmount(n.NodeChildren)
+		}
+
+		n.active = active
+		n.NodeChildren = []Node{}
+		if active != nil {
+			n.NodeChildren = active.Build()
+			Link(n.NodeChildren, n)
+		}
+		built = true
+	}
+
+	for _, sub := range n.NodeChildren {
+		sub.Refresh()
+	}
+
+	if built && n.mounted {
+		Mount(n.NodeChildren)
+	}
+}
+
+// ListItem represents single element of the w-for collection
+type ListItem struct {
+	Key   string
+	Value interface{}
+	Index interface{}
+}
+
+// ListEntry represents nodes built for the single list item
+type ListEntry struct {
+	Key    string
+	Nodes  []Node
+	Update func(ListItem)
+}
+
+// ListNode renders list entry for every item of the collection.
+// Entries are reconciled by item key, existing entries are updated in place
+// and only new items get their nodes built, nodes of removed items are unmounted.
+type ListNode struct {
+	Items        func() []ListItem
+	Build        func(ListItem) *ListEntry
+	NodeChildren []Node
+	entries      []*ListEntry
+	mounted      bool
+	parent       Node
+}
+
+func (n *ListNode) Tag() string                      { return "#for" }
+func (n *ListNode) Parent() Node                     { return n.parent }
+func (n *ListNode) SetParent(p Node)                 { n.parent = p }
+func (n *ListNode) Children() []Node                 { return n.NodeChildren }
+func (n *ListNode) Body() []Node                     { return []Node{} }
+func (n *ListNode) Props() []Attribute               { return []Attribute{} }
+func (n *ListNode) Refresh()                         { n.Notify() }
+func (n *ListNode) Handle(string, *event.Event) bool { return false }
+func (n *ListNode) Entries() []*ListEntry            { return n.entries }
+
+func (n *ListNode) Notify() {
+	// entries with duplicated keys are reused in the order they were rendered
+	previous := n.entries
+	existing := make(map[string][]*ListEntry, len(n.entries))
+	for _, entry := range previous {
+		existing[entry.Key] = append(existing[entry.Key], entry)
+	}
+
+	items := n.Items()
+	entries := make([]*ListEntry, 0, len(items))
+	children := make([]Node, 0, len(items))
+	built := make([]Node, 0)
+	reused := make(map[*ListEntry]bool, len(previous))
+
+	for _, item := range items {
+		if n.hasKey(entries, item.Key) {
+			log.Printf("Duplicate w-for key %s", item.Key)
+		}
+
+		var entry *ListEntry
+		if same := existing[item.Key]; len(same) > 0 {
+			entry = same[0]
+			existing[item.Key] = same[1:]
+			reused[entry] = true
+			entry.Update(item)
+		} else {
+			entry = n.Build(item)
+			Link(entry.Nodes, n)
+			built = append(built, entry.Nodes...)
+		}
+
+		entries = append(entries, entry)
+		children = append(children, entry.Nodes...)
+	}
+
+	n.entries = entries
+	n.NodeChildren = children
+
+	if n.mounted {
+		for _, entry := range previous {
+			if !reused[entry] {
+				Unmount(entry.Nodes)
+			}
+		}
+	}
+
+	for _, sub := range n.NodeChildren {
+		sub.Refresh()
+	}
+
+	if n.mounted {
+		Mount(built)
+	}
+}
+
+func (n *ListNode) hasKey(entries []*ListEntry, key string) bool {
+	for _, entry := range entries {
+		if entry.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SlotNode places component body nodes in to the component template.
+// Body nodes are refreshed by the component that owns them,
+// so slot refreshes only its own fallback content.
+type SlotNode struct {
+	Name         string
+	NodeChildren []Node
+	Fallback     bool
+	parent       Node
+}
+
+func (n *SlotNode) Tag() string                      { return "slot" }
+func (n *SlotNode) Parent() Node                     { return n.parent }
+func (n *SlotNode) SetParent(p Node)                 { n.parent = p }
+func (n *SlotNode) Children() []Node                 { return n.NodeChildren }
+func (n *SlotNode) Body() []Node                     { return []Node{} }
+func (n *SlotNode) Props() []Attribute               { return []Attribute{} }
+func (n *SlotNode) Refresh()                         { n.Notify() }
+func (n *SlotNode) Handle(string, *event.Event) bool { return false }
+
+func (n *SlotNode) Notify() {
+	if !n.Fallback {
+		return
+	}
+
+	for _, sub := range n.NodeChildren {
+		sub.Refresh()
+	}
+}
+
 type ComponentNode struct {
 	NodeHandlers []*Handler
 	NodeTag      string
@@ -81,15 +338,32 @@ type ComponentNode struct {
 	NodeBody     []Node
 	NodeProps    []Attribute
 	Instance     *component.Wrapper
+	mounted      bool
+	parent       Node
 }
 
+// Notify refreshes component template and body,
+// mounted component gets its update hooks called around the refresh
 func (n *ComponentNode) Notify() {
+	if n.Instance != nil {
+		n.Instance.Invalidate()
+	}
+
+	hooks := n.mounted && n.Instance != nil
+	if hooks {
+		n.Instance.BeforeUpdate()
+	}
+
 	for _, sub := range n.NodeChildren {
 		sub.Refresh()
 	}
 	for _, sub := range n.NodeBody {
 		sub.Refresh()
 	}
+
+	if hooks {
+		n.Instance.Updated()
+	}
 }
 
 func (n *ComponentNode) Refresh() {
@@ -101,7 +375,7 @@ func (n *ComponentNode) Refresh() {
 
 func (n *ComponentNode) Handle(name string, e *event.Event) bool {
 	for _, handle := range n.NodeHandlers {
-		if handle.Key == name {
+		if handle.Key == name && !handle.Capture {
 			handle.F(e)
 			return true
 		}
@@ -110,12 +384,18 @@ func (n *ComponentNode) Handle(name string, e *event.Event) bool {
 	return false
 }
 
-func (n *ComponentNode) Tag() string        { return n.NodeTag }
-func (n *ComponentNode) Children() []Node   { return n.NodeChildren }
-func (n *ComponentNode) Body() []Node       { return n.NodeBody }
-func (n *ComponentNode) Props() []Attribute { return n.NodeProps }
+func (n *ComponentNode) Tag() string          { return n.NodeTag }
+func (n *ComponentNode) Parent() Node         { return n.parent }
+func (n *ComponentNode) SetParent(p Node)     { n.parent = p }
+func (n *ComponentNode) Children() []Node     { return n.NodeChildren }
+func (n *ComponentNode) Body() []Node         { return n.NodeBody }
+func (n *ComponentNode) Props() []Attribute   { return n.NodeProps }
+func (n *ComponentNode) Handlers() []*Handler { return n.NodeHandlers }
 
+// Handler represents event handler of the node,
+// capture handlers are called while event travels down to the target
 type Handler struct {
-	Key string
-	F   func(*event.Event)
+	Key     string
+	F       func(*event.Event)
+	Capture bool
 }
@@ -0,0 +1,47 @@
+package vdom
+
+// Apply applies patches to the copy of the nodes and returns resulting nodes,
+// original nodes are left untouched
+func Apply(nodes []*VNode, patches []Patch) []*VNode {
+	root := &VNode{Children: cloneAll(nodes)}
+
+	for _, p := range patches {
+		target := root
+		for _, i := range p.Path {
+			target = target.Children[i]
+		}
+
+		switch p.Op {
+		case SetAttribute:
+			found := false
+			for i, attr := range target.Attrs {
+				if attr.Key == p.Key {
+					target.Attrs[i].Value = p.Value
+					found = true
+				}
+			}
+			if !found {
+				target.Attrs = append(target.Attrs, Attr{Key: p.Key, Value: p.Value})
+			}
+		case RemoveAttribute:
+			for i, attr := range target.Attrs {
+				if attr.Key == p.Key {
+					target.Attrs = append(target.Attrs[:i], target.Attrs[i+1:]...)
+					break
+				}
+			}
+		case SetText:
+			target.Text = p.Value
+		case InsertChild:
+			target.Children = insert(target.Children, p.Index, p.Node.Clone())
+		case RemoveChild:
+			target.Children = append(target.Children[:p.Index], target.Children[p.Index+1:]...)
+		case MoveChild:
+			moved := target.Children[p.From]
+			target.Children = append(target.Children[:p.From], target.Children[p.From+1:]...)
+			target.Children = insert(target.Children, p.Index, moved)
+		}
+	}
+
+	return root.Children
+}
@@ -0,0 +1,243 @@
+package vdom
+
+import (
+	"fmt"
+)
+
+// Op represents patch operation type
+type Op int
+
+const (
+	SetAttribute Op = iota
+	RemoveAttribute
+	SetText
+	InsertChild
+	RemoveChild
+	MoveChild
+)
+
+func (op Op) String() string {
+	switch op {
+	case SetAttribute:
+		return "set-attribute"
+	case RemoveAttribute:
+		return "remove-attribute"
+	case SetText:
+		return "set-text"
+	case InsertChild:
+		return "insert-child"
+	case RemoveChild:
+		return "remove-child"
+	case MoveChild:
+		return "move-child"
+	}
+
+	return fmt.Sprintf("op(%d)", int(op))
+}
+
+// Patch represents single change of the rendered state.
+// Path is a list of child indexes starting from the root nodes,
+// it points to the changed node for attribute and text patches
+// and to the parent node for child patches, empty path points to the root.
+// Patches are meant to be applied in order,
+// every path is valid for the state left by the previous patch.
+type Patch struct {
+	Op    Op
+	Path  []int
+	Key   string
+	Value string
+	Index int
+	From  int
+	Node  *VNode
+}
+
+func (p Patch) String() string {
+	switch p.Op {
+	case SetAttribute:
+		return fmt.Sprintf("%s %v %s=%q", p.Op, p.Path, p.Key, p.Value)
+	case RemoveAttribute:
+		return fmt.Sprintf("%s %v %s", p.Op, p.Path, p.Key)
+	case SetText:
+		return fmt.Sprintf("%s %v %q", p.Op, p.Path, p.Value)
+	case InsertChild:
+		return fmt.Sprintf("%s %v %d <%s>", p.Op, p.Path, p.Index, p.Node.Tag)
+	case RemoveChild:
+		return fmt.Sprintf("%s %v %d", p.Op, p.Path, p.Index)
+	case MoveChild:
+		return fmt.Sprintf("%s %v %d->%d", p.Op, p.Path, p.From, p.Index)
+	}
+
+	return p.Op.String()
+}
+
+type differ struct {
+	patches []Patch
+}
+
+// Diff computes minimal list of patches that turns old rendered state in to the new one
+func Diff(old, new []*VNode) []Patch {
+	d := &differ{patches: make([]Patch, 0)}
+	d.children([]int{}, old, new)
+
+	return d.patches
+}
+
+func (d *differ) add(p Patch) {
+	p.Path = append([]int{}, p.Path...)
+	d.patches = append(d.patches, p)
+}
+
+func (d *differ) node(path []int, old, new *VNode) {
+	if new.IsText() {
+		if old.Text != new.Text {
+			d.add(Patch{Op: SetText, Path: path, Value: new.Text})
+		}
+		return
+	}
+
+	for _, attr := range new.Attrs {
+		value, ok := old.Attr(attr.Key)
+		if !ok || value != attr.Value {
+			d.add(Patch{Op: SetAttribute, Path: path, Key: attr.Key, Value: attr.Value})
+		}
+	}
+
+	for _, attr := range old.Attrs {
+		if _, ok := new.Attr(attr.Key); !ok {
+			d.add(Patch{Op: RemoveAttribute, Path: path, Key: attr.Key})
+		}
+	}
+
+	d.children(path, old.Children, new.Children)
+}
+
+func sameKind(a, b *VNode) bool {
+	return a.Tag == b.Tag
+}
+
+func childPath(path []int, i int) []int {
+	return append(append([]int{}, path...), i)
+}
+
+func (d *differ) children(path []int, old, new []*VNode) {
+	if hasKeys(old) || hasKeys(new) {
+		d.keyedChildren(path, old, new)
+		return
+	}
+
+	for i := 0; i < len(old) && i < len(new); i++ {
+		if sameKind(old[i], new[i]) {
+			d.node(childPath(path, i), old[i], new[i])
+		} else {
+			d.add(Patch{Op: RemoveChild, Path: path, Index: i})
+			d.add(Patch{Op: InsertChild, Path: path, Index: i, Node: new[i]})
+		}
+	}
+
+	for i := len(old); i < len(new); i++ {
+		d.add(Patch{Op: InsertChild, Path: path, Index: i, Node: new[i]})
+	}
+
+	for i := len(old) - 1; i >= len(new); i-- {
+		d.add(Patch{Op: RemoveChild, Path: path, Index: i})
+	}
+}
+
+func hasKeys(nodes []*VNode) bool {
+	for _, n := range nodes {
+		if n.Key != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// identities assigns every node an identity used for matching,
+// keyed nodes are identified by key, others by tag and position among unkeyed nodes,
+// duplicated keys are told apart by their position among nodes with the same key
+func identities(nodes []*VNode) []string {
+	ids := make([]string, 0, len(nodes))
+	counters := make(map[string]int, 0)
+	keys := make(map[string]int, 0)
+
+	for _, n := range nodes {
+		if n.Key != "" {
+			id := "key:" + n.Key
+			if keys[n.Key] > 0 {
+				id = fmt.Sprintf("key#%d:%s", keys[n.Key], n.Key)
+			}
+			keys[n.Key]++
+			ids = append(ids, id)
+			continue
+		}
+
+		ids = append(ids, fmt.Sprintf("tag:%s:%d", n.Tag, counters[n.Tag]))
+		counters[n.Tag]++
+	}
+
+	return ids
+}
+
+func (d *differ) keyedChildren(path []int, old, new []*VNode) {
+	newIDs := identities(new)
+	wanted := make(map[string]*VNode, len(new))
+	for i, id := range newIDs {
+		wanted[id] = new[i]
+	}
+
+	current := append([]*VNode{}, old...)
+	currentIDs := identities(old)
+
+	for j := len(current) - 1; j >= 0; j-- {
+		n, ok := wanted[currentIDs[j]]
+		if ok && sameKind(n, current[j]) {
+			continue
+		}
+
+		d.add(Patch{Op: RemoveChild, Path: path, Index: j})
+		current = append(current[:j], current[j+1:]...)
+		currentIDs = append(currentIDs[:j], currentIDs[j+1:]...)
+	}
+
+	for i, n := range new {
+		j := -1
+		for k := i; k < len(current); k++ {
+			if currentIDs[k] == newIDs[i] {
+				j = k
+				break
+			}
+		}
+
+		switch {
+		case j == -1:
+			d.add(Patch{Op: InsertChild, Path: path, Index: i, Node: n})
+			current = insert(current, i, n)
+			currentIDs = insertID(currentIDs, i, newIDs[i])
+			continue
+		case j != i:
+			d.add(Patch{Op: MoveChild, Path: path, From: j, Index: i})
+			moved, movedID := current[j], currentIDs[j]
+			current = insert(append(current[:j], current[j+1:]...), i, moved)
+			currentIDs = insertID(append(currentIDs[:j], currentIDs[j+1:]...), i, movedID)
+		}
+
+		d.node(childPath(path, i), current[i], n)
+	}
+}
+
+func insert(nodes []*VNode, i int, n *VNode) []*VNode {
+	nodes = append(nodes, nil)
+	copy(nodes[i+1:], nodes[i:])
+	nodes[i] = n
+
+	return nodes
+}
+
+func insertID(ids []string, i int, id string) []string {
+	ids = append(ids, "")
+	copy(ids[i+1:], ids[i:])
+	ids[i] = id
+
+	return ids
+}
@@ -0,0 +1,176 @@
+package vdom
+
+import (
+	"github.com/Gonzih/wasm-mk2/dom"
+	"github.com/Gonzih/wasm-mk2/event"
+	"github.com/Gonzih/wasm-mk2/tree"
+)
+
+// handle mirrors rendered vnode and keeps its backend node
+type handle struct {
+	node     dom.Node
+	source   tree.Node
+	children []*handle
+}
+
+// Target keeps document backend nodes in sync with rendered vnodes,
+// events are delegated to the tree through a single listener on the root node
+type Target struct {
+	backend   dom.DOMHepler
+	root      *handle
+	listening map[string]bool
+}
+
+// NewTarget creates target that renders in to the root node of the backend
+func NewTarget(backend dom.DOMHepler, root dom.Node) *Target {
+	return &Target{
+		backend:   backend,
+		root:      &handle{node: root},
+		listening: make(map[string]bool, 0),
+	}
+}
+
+// Listen adds root listener for every event that is not listened to yet,
+// event is dispatched through the tree starting from the node it was fired on
+func (t *Target) Listen(names []string) {
+	for _, name := range names {
+		if t.listening[name] {
+			continue
+		}
+
+		t.listening[name] = true
+		name := name
+		t.backend.AddEventListener(t.root.node, name, func(e *event.Event) {
+			path := t.find(t.root.children, e.Target)
+			if len(path) == 0 || path[len(path)-1].source == nil {
+				return
+			}
+
+			elements := make(map[tree.Node]dom.Node, len(path))
+			for _, h := range path {
+				if h.source != nil {
+					elements[h.source] = h.node
+				}
+			}
+
+			tree.Dispatch(path[len(path)-1].source, name, e, func(n tree.Node) interface{} {
+				return elements[n]
+			})
+		})
+	}
+}
+
+// find looks for the handle of the backend node among handles and their descendants,
+// it returns handles on the way from the top level handle to the found one
+func (t *Target) find(handles []*handle, node dom.Node) []*handle {
+	if node == nil {
+		return nil
+	}
+
+	for _, h := range handles {
+		if t.backend.IsSameNode(h.node, node) {
+			return []*handle{h}
+		}
+
+		if found := t.find(h.children, node); found != nil {
+			return append([]*handle{h}, found...)
+		}
+	}
+
+	return nil
+}
+
+// Render creates backend nodes for every vnode and appends them to the root
+func (t *Target) Render(nodes []*VNode) {
+	for _, n := range nodes {
+		t.insert(t.root, len(t.root.children), n)
+	}
+}
+
+// Apply applies patches to the backend nodes,
+// rendered is a state patches were computed for
+// and is used to pick up changed event handlers of the elements
+func (t *Target) Apply(patches []Patch, rendered []*VNode) {
+	for _, p := range patches {
+		target := t.root
+		for _, i := range p.Path {
+			target = target.children[i]
+		}
+
+		switch p.Op {
+		case SetAttribute:
+			t.backend.SetAttribute(target.node, p.Key, p.Value)
+		case RemoveAttribute:
+			t.backend.RemoveAttribute(target.node, p.Key)
+		case SetText:
+			t.backend.SetText(target.node, p.Value)
+		case InsertChild:
+			t.insert(target, p.Index, p.Node)
+		case RemoveChild:
+			child := target.children[p.Index]
+			t.backend.RemoveChild(target.node, child.node)
+			target.children = append(target.children[:p.Index], target.children[p.Index+1:]...)
+		case MoveChild:
+			child := target.children[p.From]
+			t.backend.RemoveChild(target.node, child.node)
+			target.children = append(target.children[:p.From], target.children[p.From+1:]...)
+			t.backend.InsertChild(target.node, child.node, p.Index)
+			target.children = insertHandle(target.children, p.Index, child)
+		}
+	}
+
+	t.sync(t.root.children, rendered)
+}
+
+func (t *Target) insert(parent *handle, index int, n *VNode) {
+	h := t.create(n)
+	t.backend.InsertChild(parent.node, h.node, index)
+	parent.children = insertHandle(parent.children, index, h)
+}
+
+func (t *Target) create(n *VNode) *handle {
+	if n.IsText() {
+		return &handle{
+			node:   t.backend.CreateTextNode(n.Text),
+			source: n.Source,
+		}
+	}
+
+	h := &handle{
+		node:   t.backend.CreateElement(n.Tag),
+		source: n.Source,
+	}
+
+	for i, ch := range n.Children {
+		child := t.create(ch)
+		t.backend.InsertChild(h.node, child.node, i)
+		h.children = append(h.children, child)
+	}
+
+	// attributes are set once children exist, so select value could pick one of its options
+	for _, attr := range n.Attrs {
+		t.backend.SetAttribute(h.node, attr.Key, attr.Value)
+	}
+
+	return h
+}
+
+// sync points handles to the tree nodes of the latest rendered state
+func (t *Target) sync(handles []*handle, nodes []*VNode) {
+	for i, h := range handles {
+		if i >= len(nodes) {
+			continue
+		}
+
+		h.source = nodes[i].Source
+		t.sync(h.children, nodes[i].Children)
+	}
+}
+
+func insertHandle(handles []*handle, i int, h *handle) []*handle {
+	handles = append(handles, nil)
+	copy(handles[i+1:], handles[i:])
+	handles[i] = h
+
+	return handles
+}
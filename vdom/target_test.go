@@ -0,0 +1,85 @@
+package vdom
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/Gonzih/wasm-mk2/dom"
+	"github.com/stretchr/testify/require"
+)
+
+func fromElements(elements []*dom.Element) []*VNode {
+	nodes := make([]*VNode, 0)
+	for _, e := range elements {
+		if e.IsText() {
+			nodes = append(nodes, text(e.Text))
+			continue
+		}
+
+		attrs := make([]Attr, 0)
+		for _, attr := range e.Attrs {
+			attrs = append(attrs, Attr{attr.Key, attr.Value})
+		}
+		nodes = append(nodes, el(e.Tag, attrs, fromElements(e.Children)...))
+	}
+
+	return nodes
+}
+
+func withoutKeys(nodes []*VNode) []*VNode {
+	result := cloneAll(nodes)
+	for _, n := range result {
+		n.Key = ""
+		n.Children = withoutKeys(n.Children)
+	}
+
+	return result
+}
+
+func TestTarget(t *testing.T) {
+	backend := &dom.DOM{Document: dom.NewElement(dom.DocumentTag)}
+	root := backend.MountPoint("app").(*dom.Element)
+	target := NewTarget(backend, root)
+
+	r := rand.New(rand.NewSource(7))
+	list := func() []*VNode {
+		nodes := make([]*VNode, 0)
+		for _, i := range r.Perm(8)[:r.Intn(8)] {
+			key := strconv.Itoa(i)
+			nodes = append(nodes, keyed(key, el("li", []Attr{{"data-v", strconv.Itoa(r.Intn(3))}}, text(key+strconv.Itoa(r.Intn(2))))))
+		}
+		return []*VNode{el("ul", []Attr{}, nodes...), text(strconv.Itoa(r.Intn(2)))}
+	}
+
+	rendered := list()
+	target.Render(rendered)
+	require.Equal(t, withoutKeys(rendered), fromElements(root.Children))
+
+	for i := 0; i < 100; i++ {
+		next := list()
+		target.Apply(Diff(rendered, next), next)
+		require.Equal(t, withoutKeys(next), fromElements(root.Children))
+		rendered = next
+	}
+}
+
+// orderBackend records number of children elements had when their attributes were set
+type orderBackend struct {
+	*dom.DOM
+	children map[string]int
+}
+
+func (b *orderBackend) SetAttribute(node dom.Node, key, value string) {
+	b.children[key] = len(node.(*dom.Element).Children)
+	b.DOM.SetAttribute(node, key, value)
+}
+
+func TestTargetSetsAttributesAfterChildren(t *testing.T) {
+	backend := &orderBackend{DOM: &dom.DOM{Document: dom.NewElement(dom.DocumentTag)}, children: map[string]int{}}
+	root := backend.MountPoint("app")
+	target := NewTarget(backend, root)
+
+	target.Render([]*VNode{el("select", []Attr{{"value", "b"}}, el("option", []Attr{{"data-v", "a"}}), el("option", []Attr{{"data-v", "b"}}))})
+	require.Equal(t, 2, backend.children["value"])
+}
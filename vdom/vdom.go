@@ -0,0 +1,116 @@
+// Package vdom captures rendered state of the runtime tree
+// and computes patches needed to turn one rendered state in to another
+package vdom
+
+import (
+	"fmt"
+
+	"github.com/Gonzih/wasm-mk2/tree"
+)
+
+// TextTag is used as a tag of the text vnodes
+const TextTag = "#text"
+
+// Attr represents rendered attribute
+type Attr struct {
+	Key   string
+	Value string
+}
+
+// VNode represents rendered html element or text,
+// Source holds tree node element was rendered from
+type VNode struct {
+	Tag      string
+	Text     string
+	Attrs    []Attr
+	Children []*VNode
+	Key      string
+	Source   tree.Node
+}
+
+// IsText checks if vnode represents text
+func (v *VNode) IsText() bool {
+	return v.Tag == TextTag
+}
+
+// Attr looks up attribute value
+func (v *VNode) Attr(key string) (string, bool) {
+	for _, attr := range v.Attrs {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Clone creates deep copy of the vnode
+func (v *VNode) Clone() *VNode {
+	cpy := *v
+	if v.Attrs != nil {
+		cpy.Attrs = append([]Attr{}, v.Attrs...)
+	}
+	cpy.Children = cloneAll(v.Children)
+
+	return &cpy
+}
+
+func cloneAll(nodes []*VNode) []*VNode {
+	if nodes == nil {
+		return nil
+	}
+
+	result := make([]*VNode, 0, len(nodes))
+	for _, n := range nodes {
+		result = append(result, n.Clone())
+	}
+
+	return result
+}
+
+type textNode interface {
+	Text() string
+}
+
+// Snapshot captures current rendered state of the nodes,
+// components, conditionals, lists and slots are flattened in to their output
+// and top level nodes of every list entry are keyed by the entry key
+func Snapshot(nodes []tree.Node) []*VNode {
+	result := make([]*VNode, 0, len(nodes))
+
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case textNode:
+			result = append(result, &VNode{Tag: TextTag, Text: n.Text(), Source: node})
+		case *tree.HTMLNode:
+			vnode := &VNode{
+				Tag:      n.Tag(),
+				Attrs:    make([]Attr, 0, len(n.Props())),
+				Children: Snapshot(n.Children()),
+				Source:   n,
+			}
+			for _, attr := range n.Props() {
+				if !tree.IsPresent(attr) {
+					continue
+				}
+				vnode.Attrs = append(vnode.Attrs, Attr{Key: attr.Key(), Value: attr.Value()})
+			}
+			result = append(result, vnode)
+		case *tree.ListNode:
+			for _, entry := range n.Entries() {
+				vnodes := Snapshot(entry.Nodes)
+				for i, vnode := range vnodes {
+					vnode.Key = entry.Key
+					if len(vnodes) > 1 {
+						vnode.Key = fmt.Sprintf("%s#%d", entry.Key, i)
+					}
+				}
+				result = append(result, vnodes...)
+			}
+		default:
+			result = append(result, Snapshot(node.Children())...)
+		}
+	}
+
+	return result
+}
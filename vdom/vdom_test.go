@@ -0,0 +1,177 @@
+package vdom
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/Gonzih/wasm-mk2/component"
+	"github.com/Gonzih/wasm-mk2/dom"
+	"github.com/Gonzih/wasm-mk2/registry"
+	"github.com/Gonzih/wasm-mk2/scope"
+	"github.com/Gonzih/wasm-mk2/walker"
+	"github.com/stretchr/testify/require"
+)
+
+func el(tag string, attrs []Attr, children ...*VNode) *VNode {
+	return &VNode{Tag: tag, Attrs: attrs, Children: children}
+}
+
+func text(s string) *VNode {
+	return &VNode{Tag: TextTag, Text: s}
+}
+
+func keyed(key string, n *VNode) *VNode {
+	n.Key = key
+	return n
+}
+
+func checkDiff(t *testing.T, old, new []*VNode, expected []Patch) {
+	patches := Diff(old, new)
+	require.Equal(t, expected, patches)
+	require.Equal(t, new, Apply(old, patches))
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	old := []*VNode{el("div", []Attr{{"class", "a"}}, text("hi"))}
+	new := []*VNode{el("div", []Attr{{"class", "a"}}, text("hi"))}
+
+	checkDiff(t, old, new, []Patch{})
+}
+
+func TestDiffTextAndAttributes(t *testing.T) {
+	old := []*VNode{el("div", []Attr{{"class", "a"}, {"id", "x"}}, el("p", []Attr{}, text("hi")))}
+	new := []*VNode{el("div", []Attr{{"class", "b"}, {"title", "t"}}, el("p", []Attr{}, text("bye")))}
+
+	checkDiff(t, old, new, []Patch{
+		{Op: SetAttribute, Path: []int{0}, Key: "class", Value: "b"},
+		{Op: SetAttribute, Path: []int{0}, Key: "title", Value: "t"},
+		{Op: RemoveAttribute, Path: []int{0}, Key: "id"},
+		{Op: SetText, Path: []int{0, 0, 0}, Value: "bye"},
+	})
+}
+
+func TestDiffUnkeyedChildren(t *testing.T) {
+	old := []*VNode{el("ul", []Attr{}, el("li", []Attr{}), el("li", []Attr{}), el("li", []Attr{}))}
+	new := []*VNode{el("ul", []Attr{}, el("li", []Attr{}), el("p", []Attr{}))}
+
+	checkDiff(t, old, new, []Patch{
+		{Op: RemoveChild, Path: []int{0}, Index: 1},
+		{Op: InsertChild, Path: []int{0}, Index: 1, Node: new[0].Children[1]},
+		{Op: RemoveChild, Path: []int{0}, Index: 2},
+	})
+
+	checkDiff(t, new, old, []Patch{
+		{Op: RemoveChild, Path: []int{0}, Index: 1},
+		{Op: InsertChild, Path: []int{0}, Index: 1, Node: old[0].Children[1]},
+		{Op: InsertChild, Path: []int{0}, Index: 2, Node: old[0].Children[2]},
+	})
+}
+
+func TestDiffKeyedChildren(t *testing.T) {
+	old := []*VNode{
+		keyed("a", el("li", []Attr{}, text("a"))),
+		keyed("b", el("li", []Attr{}, text("b"))),
+		keyed("c", el("li", []Attr{}, text("c"))),
+	}
+	new := []*VNode{
+		keyed("c", el("li", []Attr{}, text("c!"))),
+		keyed("d", el("li", []Attr{}, text("d"))),
+		keyed("a", el("li", []Attr{}, text("a"))),
+	}
+
+	checkDiff(t, old, new, []Patch{
+		{Op: RemoveChild, Path: []int{}, Index: 1},
+		{Op: MoveChild, Path: []int{}, From: 1, Index: 0},
+		{Op: SetText, Path: []int{0, 0}, Value: "c!"},
+		{Op: InsertChild, Path: []int{}, Index: 1, Node: new[1]},
+	})
+}
+
+func TestDiffDuplicateKeys(t *testing.T) {
+	old := []*VNode{
+		keyed("a", el("li", []Attr{}, text("a"))),
+		keyed("a", el("li", []Attr{}, text("a"))),
+	}
+	new := []*VNode{
+		keyed("a", el("li", []Attr{}, text("a"))),
+	}
+
+	checkDiff(t, old, new, []Patch{
+		{Op: RemoveChild, Path: []int{}, Index: 1},
+	})
+	checkDiff(t, new, old, []Patch{
+		{Op: InsertChild, Path: []int{}, Index: 1, Node: old[1]},
+	})
+}
+
+func TestDiffKeyedChildrenRandomPermutations(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+
+	list := func() []*VNode {
+		nodes := make([]*VNode, 0)
+		for _, i := range r.Perm(10)[:r.Intn(10)] {
+			key := strconv.Itoa(i)
+			nodes = append(nodes, keyed(key, el("li", []Attr{{"data-v", strconv.Itoa(r.Intn(2))}}, text(key))))
+		}
+		return nodes
+	}
+
+	for i := 0; i < 200; i++ {
+		old, new := list(), list()
+		patches := Diff(old, new)
+		require.Equal(t, new, Apply(old, patches))
+
+		for _, p := range patches {
+			require.NotEqual(t, SetText, p.Op)
+		}
+	}
+}
+
+type TodoList struct {
+	Todos []string `wasm:"state"`
+}
+
+func (c *TodoList) Init() error {
+	c.Todos = []string{"a", "b"}
+	return nil
+}
+
+func TestSnapshot(t *testing.T) {
+	wrapper, err := component.Wasmify(&TodoList{})
+	require.Nil(t, err)
+	registry.Register("todo-list", wrapper)
+	registry.RegisterTemplate("todo-list", "todo-list-template")
+	dom.RegisterMockTemplate("todo-list-template", `<ul class="todos"><li w-for="todo in Todos" :key="todo">{{ todo }}</li><li w-if="false">never</li></ul>`)
+	dom.RegisterMockTemplate("app-root", `<todo-list></todo-list>text`)
+
+	w := walker.NewByID("app-root")
+	nodes := w.WalkAST(scope.Empty())
+	require.Len(t, w.Errors(), 0)
+
+	snapshot := Snapshot(nodes)
+	require.Equal(t, nodes[0].Children()[0], snapshot[0].Source)
+	require.Equal(t, nodes[0].Children()[0].Children()[0].Children()[1], snapshot[0].Children[1].Source)
+	clearSources(snapshot)
+
+	require.Equal(t, []*VNode{
+		el("ul", []Attr{{"class", "todos"}},
+			keyed("a", el("li", []Attr{}, text("a"))),
+			keyed("b", el("li", []Attr{}, text("b"))),
+		),
+		text("text"),
+	}, snapshot)
+}
+
+func clearSources(nodes []*VNode) {
+	for _, n := range nodes {
+		n.Source = nil
+		clearSources(n.Children)
+	}
+}
+
+func TestPatchString(t *testing.T) {
+	require.Equal(t, `set-attribute [0 1] class="a"`, Patch{Op: SetAttribute, Path: []int{0, 1}, Key: "class", Value: "a"}.String())
+	require.Equal(t, `move-child [] 3->0`, Patch{Op: MoveChild, Path: []int{}, From: 3, Index: 0}.String())
+	require.Equal(t, `insert-child [0] 1 <li>`, Patch{Op: InsertChild, Path: []int{0}, Index: 1, Node: el("li", nil)}.String())
+}
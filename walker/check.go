@@ -0,0 +1,49 @@
+package walker
+
+import (
+	"github.com/Gonzih/wasm-mk2/ast"
+	"github.com/Gonzih/wasm-mk2/registry"
+	"github.com/Gonzih/wasm-mk2/scope"
+	"github.com/Gonzih/wasm-mk2/tree"
+)
+
+// check walks nodes without creating component instances
+// to report errors of templates that are built later, like w-if branches and w-for items
+func (w *Walker) check(nodes []ast.Node, scope *scope.Scope) {
+	if w.checked {
+		return
+	}
+
+	dry := w.dry
+	w.dry = true
+	w.walkComponent(nodes, scope)
+	w.dry = dry
+}
+
+// walkChecked walks nodes that were already checked
+func (w *Walker) walkChecked(nodes []ast.Node, scope *scope.Scope) []tree.Node {
+	checked := w.checked
+	w.checked = true
+	defer func() { w.checked = checked }()
+
+	return w.walkComponent(nodes, scope)
+}
+
+// checkComponent checks attributes and body of the component tag in the parent scope,
+// component template is checked once component is created
+func (w *Walker) checkComponent(node ast.Node, parentScope *scope.Scope) tree.Node {
+	if _, ok := registry.TemplateID(node.Tag()); !ok {
+		w.fail(&MissingTemplateError{
+			TemplateID: w.templateID,
+			Pos:        node.Position(),
+			Component:  node.Tag(),
+		})
+	}
+
+	return &tree.ComponentNode{
+		NodeTag:      node.Tag(),
+		NodeProps:    w.convertProperties(node, parentScope, nil),
+		NodeHandlers: w.convertHandlers(node, parentScope),
+		NodeBody:     w.walkComponent(node.Children(), parentScope),
+	}
+}
@@ -0,0 +1,137 @@
+package walker
+
+import (
+	"fmt"
+
+	"github.com/Gonzih/wasm-mk2/ast"
+)
+
+func location(templateID string, pos ast.Position) string {
+	if templateID == "" {
+		return pos.String()
+	}
+
+	return fmt.Sprintf("%s:%s", templateID, pos)
+}
+
+// UnknownHandlerError is reported when event handler attribute
+// references method that component does not have
+type UnknownHandlerError struct {
+	TemplateID string
+	Pos        ast.Position
+	Event      string
+	Handler    string
+}
+
+func (e *UnknownHandlerError) Error() string {
+	return fmt.Sprintf("%s: unknown handler %s for @%s", location(e.TemplateID, e.Pos), e.Handler, e.Event)
+}
+
+// UnknownFieldError is reported when template references field
+// that is not available in the scope or prop that could not be set
+type UnknownFieldError struct {
+	TemplateID string
+	Pos        ast.Position
+	Field      string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("%s: unknown field %s", location(e.TemplateID, e.Pos), e.Field)
+}
+
+// MissingTemplateError is reported when registered component has no template
+type MissingTemplateError struct {
+	TemplateID string
+	Pos        ast.Position
+	Component  string
+}
+
+func (e *MissingTemplateError) Error() string {
+	return fmt.Sprintf("%s: missing template for component <%s>", location(e.TemplateID, e.Pos), e.Component)
+}
+
+// InitError is reported when component instance could not be created,
+// usually because component Init returned an error
+type InitError struct {
+	TemplateID string
+	Pos        ast.Position
+	Component  string
+	Err        error
+}
+
+func (e *InitError) Error() string {
+	return fmt.Sprintf("%s: component <%s> failed to initialize: %s", location(e.TemplateID, e.Pos), e.Component, e.Err)
+}
+
+func (e *InitError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownModifierError is reported when event handler attribute has modifier that is not supported
+type UnknownModifierError struct {
+	TemplateID string
+	Pos        ast.Position
+	Event      string
+	Modifier   string
+}
+
+func (e *UnknownModifierError) Error() string {
+	return fmt.Sprintf("%s: unknown modifier .%s for @%s", location(e.TemplateID, e.Pos), e.Modifier, e.Event)
+}
+
+// ModelError is reported when w-model directive could not be bound to the field
+type ModelError struct {
+	TemplateID string
+	Pos        ast.Position
+	Msg        string
+}
+
+func (e *ModelError) Error() string {
+	return fmt.Sprintf("%s: %s", location(e.TemplateID, e.Pos), e.Msg)
+}
+
+// PropError is reported when value of the component tag attribute
+// could not be converted to the type of the prop
+type PropError struct {
+	TemplateID string
+	Pos        ast.Position
+	Component  string
+	Prop       string
+	Err        error
+}
+
+func (e *PropError) Error() string {
+	return fmt.Sprintf("%s: could not set prop %s of <%s>: %s", location(e.TemplateID, e.Pos), e.Prop, e.Component, e.Err)
+}
+
+func (e *PropError) Unwrap() error {
+	return e.Err
+}
+
+// MissingPropError is reported when component tag does not set a required prop
+type MissingPropError struct {
+	TemplateID string
+	Pos        ast.Position
+	Component  string
+	Prop       string
+}
+
+func (e *MissingPropError) Error() string {
+	return fmt.Sprintf("%s: missing required prop %s of <%s>", location(e.TemplateID, e.Pos), e.Prop, e.Component)
+}
+
+// ExpressionError is reported when binding, interpolation or w-for expression
+// could not be parsed or checked against the scope
+type ExpressionError struct {
+	TemplateID string
+	Pos        ast.Position
+	Err        error
+}
+
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("%s: %s", location(e.TemplateID, e.Pos), e.Err)
+}
+
+func (e *ExpressionError) Unwrap() error {
+	return e.Err
+}
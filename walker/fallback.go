@@ -0,0 +1,56 @@
+package walker
+
+import (
+	"log"
+
+	"github.com/Gonzih/wasm-mk2/ast"
+	"github.com/Gonzih/wasm-mk2/scope"
+	"github.com/Gonzih/wasm-mk2/tree"
+)
+
+const directiveFallback = "w-fallback"
+
+// isFallback checks if body node is a replacement content for the failed component
+func isFallback(node ast.Node) bool {
+	el, ok := node.(*ast.Element)
+	return ok && hasAttribute(el, directiveFallback)
+}
+
+// newFailedComponent replaces component that failed to initialize
+// with body elements marked by w-fallback walked in the parent scope,
+// failure is reported as an error only when there is no fallback content
+func (w *Walker) newFailedComponent(node ast.Node, err error, parentScope *scope.Scope) tree.Node {
+	initErr := &InitError{
+		TemplateID: w.templateID,
+		Pos:        node.Position(),
+		Component:  node.Tag(),
+		Err:        err,
+	}
+
+	fallback := make([]ast.Node, 0)
+	for _, child := range node.Children() {
+		if !isFallback(child) {
+			continue
+		}
+
+		el := *child.(*ast.Element)
+		el.HTMLAttributes = make([]ast.Attribute, 0, len(el.HTMLAttributes))
+		for _, attr := range child.Attributes() {
+			if attr.Name != directiveFallback {
+				el.HTMLAttributes = append(el.HTMLAttributes, attr)
+			}
+		}
+		fallback = append(fallback, &el)
+	}
+
+	if len(fallback) == 0 {
+		w.report(initErr)
+	} else {
+		log.Printf("Rendering fallback: %s", initErr)
+	}
+
+	return &tree.ComponentNode{
+		NodeTag:      node.Tag(),
+		NodeChildren: w.walkComponent(fallback, parentScope),
+	}
+}
@@ -0,0 +1,234 @@
+package walker
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Gonzih/wasm-mk2/ast"
+	"github.com/Gonzih/wasm-mk2/expr"
+	"github.com/Gonzih/wasm-mk2/scope"
+	"github.com/Gonzih/wasm-mk2/tree"
+)
+
+const (
+	directiveFor = "w-for"
+	directiveIf  = "w-if"
+	attributeKey = ":key"
+)
+
+// loop represents parsed w-for="item, index in Items" directive
+type loop struct {
+	item   string
+	index  string
+	source string
+}
+
+func parseLoop(src string) (*loop, error) {
+	parts := strings.SplitN(src, " in ", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid %s expression %q, expected \"item in Items\"", directiveFor, src)
+	}
+
+	vars := strings.TrimSpace(parts[0])
+	vars = strings.TrimSuffix(strings.TrimPrefix(vars, "("), ")")
+	names := strings.Split(vars, ",")
+	if len(names) > 2 {
+		return nil, fmt.Errorf("invalid %s expression %q, expected at most two loop variables", directiveFor, src)
+	}
+
+	l := &loop{
+		item:   strings.TrimSpace(names[0]),
+		source: strings.TrimSpace(parts[1]),
+	}
+
+	if len(names) == 2 {
+		l.index = strings.TrimSpace(names[1])
+	}
+
+	if l.item == "" || (len(names) == 2 && l.index == "") || l.source == "" {
+		return nil, fmt.Errorf("invalid %s expression %q, expected \"item in Items\"", directiveFor, src)
+	}
+
+	return l, nil
+}
+
+func (l *loop) vars(item tree.ListItem) map[string]interface{} {
+	vars := map[string]interface{}{l.item: item.Value}
+	if l.index != "" {
+		vars[l.index] = item.Index
+	}
+
+	return vars
+}
+
+// zeroVars returns loop variables holding zero values of the source element and index types,
+// variables are left untyped when source type is not known in advance
+func (l *loop) zeroVars(source *expr.Expression, env expr.TypeEnv) map[string]interface{} {
+	vars := l.vars(tree.ListItem{})
+	if source == nil {
+		return vars
+	}
+
+	t, err := source.Type(env)
+	if err != nil || t == nil {
+		return vars
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var item, index reflect.Type
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		item, index = t.Elem(), reflect.TypeOf(0)
+	case reflect.Map:
+		item, index = t.Elem(), t.Key()
+	default:
+		return vars
+	}
+
+	vars[l.item] = reflect.Zero(item).Interface()
+	if l.index != "" {
+		vars[l.index] = reflect.Zero(index).Interface()
+	}
+
+	return vars
+}
+
+func hasAttribute(el *ast.Element, name string) bool {
+	for _, attr := range el.HTMLAttributes {
+		if attr.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newListNode converts element with w-for directive in to list node,
+// element itself without w-for and :key attributes is built for every item,
+// w-if on the same element is evaluated for every item
+func (w *Walker) newListNode(el *ast.Element, parentScope *scope.Scope) tree.Node {
+	element := *el
+	element.HTMLAttributes = make([]ast.Attribute, 0, len(el.HTMLAttributes))
+
+	var loopSrc, keySrc, condSrc string
+	hasCond := false
+	for _, attr := range el.HTMLAttributes {
+		switch attr.Name {
+		case directiveFor:
+			loopSrc = attr.Value
+		case attributeKey:
+			keySrc = attr.Value
+		case directiveIf:
+			condSrc = attr.Value
+			hasCond = true
+		default:
+			element.HTMLAttributes = append(element.HTMLAttributes, attr)
+		}
+	}
+
+	var item ast.Node = &element
+	if hasCond {
+		item = &ast.Conditional{
+			Pos:      el.Pos,
+			Branches: []*ast.ConditionalBranch{{Condition: condSrc, Node: &element}},
+		}
+	}
+
+	l, err := parseLoop(loopSrc)
+	if err != nil {
+		w.fail(&ExpressionError{TemplateID: w.templateID, Pos: el.Pos, Err: err})
+		return &tree.ListNode{
+			Items: func() []tree.ListItem { return []tree.ListItem{} },
+		}
+	}
+
+	sourceExpr := w.compile(l.source, el.Pos, parentScope)
+	source := func() interface{} {
+		return evaluate(sourceExpr, parentScope)
+	}
+
+	// key expression and item template are checked against the scope
+	// with zero values of the loop variables, so they are typed when the source type is known
+	checkScope := scope.WithVars(parentScope, l.zeroVars(sourceExpr, parentScope))
+	var key *expr.Expression
+	if keySrc != "" {
+		key = w.compile(keySrc, el.Pos, checkScope)
+	}
+	w.check([]ast.Node{item}, checkScope)
+
+	return &tree.ListNode{
+		Items: func() []tree.ListItem {
+			items := listItems(source())
+			if key == nil {
+				return items
+			}
+
+			for i := range items {
+				items[i].Key = stringify(evaluate(key, scope.WithVars(parentScope, l.vars(items[i]))))
+			}
+
+			return items
+		},
+		Build: func(listItem tree.ListItem) *tree.ListEntry {
+			itemScope := scope.WithVars(parentScope, l.vars(listItem))
+
+			return &tree.ListEntry{
+				Key:   listItem.Key,
+				Nodes: w.walkChecked([]ast.Node{item}, itemScope),
+				Update: func(listItem tree.ListItem) {
+					for name, v := range l.vars(listItem) {
+						itemScope.Set(name, v)
+					}
+				},
+			}
+		},
+	}
+}
+
+// listItems converts slice, array or map in to list items keyed by position,
+// maps are iterated in the order of sorted keys
+func listItems(collection interface{}) []tree.ListItem {
+	items := make([]tree.ListItem, 0)
+	if collection == nil {
+		return items
+	}
+
+	val := reflect.ValueOf(collection)
+	for val.Kind() == reflect.Ptr && !val.IsNil() {
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			items = append(items, tree.ListItem{
+				Key:   strconv.Itoa(i),
+				Value: val.Index(i).Interface(),
+				Index: i,
+			})
+		}
+	case reflect.Map:
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+
+		for _, k := range keys {
+			items = append(items, tree.ListItem{
+				Key:   fmt.Sprint(k.Interface()),
+				Value: val.MapIndex(k).Interface(),
+				Index: k.Interface(),
+			})
+		}
+	default:
+		log.Printf("Could not iterate over %v of type %T", collection, collection)
+	}
+
+	return items
+}
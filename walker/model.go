@@ -0,0 +1,134 @@
+package walker
+
+import (
+	"log"
+
+	"github.com/Gonzih/wasm-mk2/ast"
+	"github.com/Gonzih/wasm-mk2/event"
+	"github.com/Gonzih/wasm-mk2/expr"
+	"github.com/Gonzih/wasm-mk2/scope"
+	"github.com/Gonzih/wasm-mk2/tree"
+)
+
+const directiveModel = "w-model"
+
+// takeModel returns copy of the element without w-model directive and the directive value
+func takeModel(el *ast.Element) (*ast.Element, string) {
+	cpy := *el
+	cpy.HTMLAttributes = make([]ast.Attribute, 0, len(el.HTMLAttributes))
+	field := ""
+
+	for _, attr := range el.HTMLAttributes {
+		if attr.Name == directiveModel {
+			field = attr.Value
+		} else {
+			cpy.HTMLAttributes = append(cpy.HTMLAttributes, attr)
+		}
+	}
+
+	return &cpy, field
+}
+
+// convertModel binds form element to the component field,
+// checkboxes and radio buttons are bound through the checked attribute and change event,
+// radio button sets field to its static or bound value,
+// selects through the value attribute and change event, multiple selects are not supported
+// and the rest of elements through the value attribute and input event
+func (w *Walker) convertModel(el *ast.Element, src string, scope *scope.Scope) ([]tree.Attribute, []*tree.Handler) {
+	e := w.compile(src, el.Pos, scope)
+	if e == nil {
+		return nil, nil
+	}
+
+	field, ok := e.Identifier()
+	if !ok {
+		w.fail(&ModelError{
+			TemplateID: w.templateID,
+			Pos:        el.Pos,
+			Msg:        "w-model requires a field name, got " + src,
+		})
+		return nil, nil
+	}
+
+	setter, ok := scope.Setter(field)
+	if !ok {
+		w.fail(&ModelError{
+			TemplateID: w.templateID,
+			Pos:        el.Pos,
+			Msg:        "w-model field " + field + " could not be set",
+		})
+		return nil, nil
+	}
+
+	get := func() interface{} {
+		return evaluate(e, scope)
+	}
+	set := func(v interface{}) {
+		if err := setter(v); err != nil {
+			log.Printf("Could not set %s: %s", field, err)
+		}
+		w.notifyComponent(w.owner)
+	}
+
+	inputType := ""
+	value := func() string { return "" }
+	multiple := false
+	for _, attr := range el.HTMLAttributes {
+		switch attr.Name {
+		case "type":
+			inputType = attr.Value
+		case "value":
+			static := attr.Value
+			value = func() string { return static }
+		case ":value":
+			if ve := w.compile(attr.Value, el.Pos, scope); ve != nil {
+				value = func() string { return stringify(evaluate(ve, scope)) }
+			}
+		case "multiple", ":multiple":
+			multiple = true
+		}
+	}
+
+	if el.HTMLTag == "select" && multiple {
+		w.fail(&ModelError{
+			TemplateID: w.templateID,
+			Pos:        el.Pos,
+			Msg:        "w-model does not support select with multiple attribute",
+		})
+		return nil, nil
+	}
+
+	var attr tree.Attribute = &tree.DynamicAttribute{
+		K: "value",
+		F: func() string { return stringify(get()) },
+	}
+	handler := &tree.Handler{
+		Key: "input",
+		F:   func(e *event.Event) { set(e.Value) },
+	}
+
+	switch {
+	case el.HTMLTag == "input" && inputType == "checkbox":
+		attr = &tree.BooleanAttribute{
+			K: "checked",
+			F: func() bool { return expr.Truthy(get()) },
+		}
+		handler.Key = "change"
+		handler.F = func(e *event.Event) { set(e.Checked) }
+	case el.HTMLTag == "input" && inputType == "radio":
+		attr = &tree.BooleanAttribute{
+			K: "checked",
+			F: func() bool { return stringify(get()) == value() },
+		}
+		handler.Key = "change"
+		handler.F = func(e *event.Event) {
+			if e.Checked {
+				set(value())
+			}
+		}
+	case el.HTMLTag == "select":
+		handler.Key = "change"
+	}
+
+	return []tree.Attribute{attr}, []*tree.Handler{handler}
+}
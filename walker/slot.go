@@ -0,0 +1,73 @@
+package walker
+
+import (
+	"github.com/Gonzih/wasm-mk2/ast"
+	"github.com/Gonzih/wasm-mk2/scope"
+	"github.com/Gonzih/wasm-mk2/tree"
+)
+
+const (
+	slotTag         = "slot"
+	slotAttribute   = "slot"
+	slotName        = "name"
+	defaultSlotName = ""
+)
+
+// walkBody walks component body and groups resulting nodes by the slot name
+// taken from the slot="name" attribute of the top level body elements,
+// w-fallback elements are skipped since component was created successfully
+func (w *Walker) walkBody(nodes []ast.Node, scope *scope.Scope) ([]tree.Node, map[string][]tree.Node) {
+	body := make([]tree.Node, 0)
+	slots := make(map[string][]tree.Node, 0)
+
+	for _, node := range nodes {
+		if isFallback(node) {
+			continue
+		}
+
+		name := defaultSlotName
+
+		if el, ok := node.(*ast.Element); ok && hasAttribute(el, slotAttribute) {
+			cpy := *el
+			cpy.HTMLAttributes = make([]ast.Attribute, 0, len(el.HTMLAttributes))
+			for _, attr := range el.HTMLAttributes {
+				if attr.Name == slotAttribute {
+					name = attr.Value
+				} else {
+					cpy.HTMLAttributes = append(cpy.HTMLAttributes, attr)
+				}
+			}
+			node = &cpy
+		}
+
+		walked := w.walkComponent([]ast.Node{node}, scope)
+		body = append(body, walked...)
+		slots[name] = append(slots[name], walked...)
+	}
+
+	return body, slots
+}
+
+// newSlotNode places body nodes of the component in to its template,
+// slot children are used as a fallback when body has nothing for the slot
+func (w *Walker) newSlotNode(el *ast.Element, scope *scope.Scope) tree.Node {
+	name := defaultSlotName
+	for _, attr := range el.HTMLAttributes {
+		if attr.Name == slotName {
+			name = attr.Value
+		}
+	}
+
+	if nodes, ok := w.slots[name]; ok {
+		return &tree.SlotNode{
+			Name:         name,
+			NodeChildren: nodes,
+		}
+	}
+
+	return &tree.SlotNode{
+		Name:         name,
+		NodeChildren: w.walkComponent(el.Children(), scope),
+		Fallback:     true,
+	}
+}
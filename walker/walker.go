@@ -6,7 +6,10 @@ import (
 	"strings"
 
 	"github.com/Gonzih/wasm-mk2/ast"
+	"github.com/Gonzih/wasm-mk2/component"
 	"github.com/Gonzih/wasm-mk2/dom"
+	"github.com/Gonzih/wasm-mk2/event"
+	"github.com/Gonzih/wasm-mk2/expr"
 	"github.com/Gonzih/wasm-mk2/parser"
 	"github.com/Gonzih/wasm-mk2/registry"
 	"github.com/Gonzih/wasm-mk2/scope"
@@ -15,62 +18,140 @@ import (
 )
 
 type Walker struct {
-	parser *parser.Parser
-	root   *ast.Root
-	errors []string
+	parser     *parser.Parser
+	root       *ast.Root
+	templateID string
+	errors     []error
+	// walked is set once the initial walk is done,
+	// errors found after that are logged instead of collected
+	walked bool
+	// dry is set while templates are only checked, components are not instantiated
+	dry bool
+	// checked is set while walking templates that were already checked,
+	// template errors are not reported twice
+	checked bool
+	// notify is called with the component node after handlers of its instance
+	notify func(*tree.ComponentNode)
+	// owner is the component node this walker is walking template of
+	owner *tree.ComponentNode
+	// slots holds body nodes of the component this walker is walking template of
+	slots map[string][]tree.Node
 }
 
 func NewByID(templateID string) *Walker {
-	input := dom.New().TemplateContent(templateID)
+	input := dom.Backend().TemplateContent(templateID)
 	r := strings.NewReader(input)
 	z := html.NewTokenizer(r)
-	p := parser.New(z)
+	p := parser.NewWithID(templateID, z)
 
 	return New(p)
 }
 
 func New(p *parser.Parser) *Walker {
 	w := &Walker{
-		parser: p,
-		root:   p.ParseTree(),
-		errors: p.Errors(),
+		parser:     p,
+		root:       p.ParseTree(),
+		templateID: p.TemplateID(),
+	}
+
+	for _, err := range p.Errors() {
+		w.errors = append(w.errors, err)
 	}
 
 	return w
 }
 
-func (w *Walker) Errors() []string {
+// SetNotifier sets function that is called after every handler
+// of the component instances created by the walker,
+// it receives node of the component which handler was called
+func (w *Walker) SetNotifier(f func(*tree.ComponentNode)) {
+	w.notify = f
+}
+
+func (w *Walker) notifyComponent(node *tree.ComponentNode) {
+	if w.notify != nil {
+		w.notify(node)
+	}
+}
+
+func (w *Walker) Errors() []error {
 	return w.errors
 }
 
+// fail reports template error unless template was already checked
+func (w *Walker) fail(err error) {
+	if w.checked {
+		return
+	}
+
+	w.report(err)
+}
+
+// report collects error found during the initial walk and logs it afterwards
+func (w *Walker) report(err error) {
+	if w.walked {
+		log.Print(err)
+		return
+	}
+
+	w.errors = append(w.errors, err)
+}
+
 func (w *Walker) WalkAST(s *scope.Scope) []tree.Node {
 	components := w.walkComponent(w.root.Children(), s)
 
 	for _, cmp := range components {
-		cmp.Notify()
+		cmp.Refresh()
 	}
 
+	tree.Link(components, nil)
+	w.walked = true
+
 	return components
 }
-func (w *Walker) convertHandlers(attrs []ast.Attribute, scope *scope.Scope) []*tree.Handler {
+
+// convertHandlers converts @event attributes of the node in to tree handlers
+// looked up in the scope, unknown handlers are reported and skipped.
+// Event name could be followed by .capture, .stop and .prevent modifiers.
+func (w *Walker) convertHandlers(node ast.Node, scope *scope.Scope) []*tree.Handler {
 	result := make([]*tree.Handler, 0)
 
-	for _, attr := range attrs {
+	for _, attr := range node.Attributes() {
 		k := attr.Name
 		v := attr.Value
 
 		if strings.HasPrefix(k, "@") {
 			if scope != nil {
 				k = strings.Replace(k, "@", "", 1)
+				modifiers := strings.Split(k, ".")
+				k = modifiers[0]
 				handler, ok := scope.Handler(v)
 				if !ok {
-					log.Fatalf("Could not find handler %s", k)
+					w.fail(&UnknownHandlerError{
+						TemplateID: w.templateID,
+						Pos:        node.Position(),
+						Event:      k,
+						Handler:    v,
+					})
+					continue
 				}
 
-				result = append(result, &tree.Handler{
+				h := &tree.Handler{
 					Key: k,
 					F:   handler,
-				})
+				}
+				for _, modifier := range modifiers[1:] {
+					if !applyModifier(h, modifier) {
+						w.fail(&UnknownModifierError{
+							TemplateID: w.templateID,
+							Pos:        node.Position(),
+							Event:      k,
+							Modifier:   modifier,
+						})
+					}
+				}
+
+				result = append(result, h)
 			}
 		}
 	}
@@ -78,23 +159,59 @@ func (w *Walker) convertHandlers(attrs []ast.Attribute, scope *scope.Scope) []*t
 	return result
 }
 
-func (w *Walker) convertProperties(attrs []ast.Attribute, scope *scope.Scope) []tree.Attribute {
+// applyModifier changes handler according to the event modifier,
+// returns false if modifier is not known
+func applyModifier(h *tree.Handler, modifier string) bool {
+	f := h.F
+
+	switch modifier {
+	case "capture":
+		h.Capture = true
+	case "stop":
+		h.F = func(e *event.Event) {
+			e.StopPropagation()
+			f(e)
+		}
+	case "prevent":
+		h.F = func(e *event.Event) {
+			e.PreventDefault()
+			f(e)
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+// convertProperties converts element attributes in to tree attributes,
+// event handlers are skipped, dynamic attributes are evaluated in the scope
+// and linked to the instance props when instance is not nil,
+// static attributes matching instance props are set once
+func (w *Walker) convertProperties(node ast.Node, scope *scope.Scope, instance *component.Wrapper) []tree.Attribute {
 	result := make([]tree.Attribute, 0)
 
-	for _, attr := range attrs {
+	for _, attr := range node.Attributes() {
 		var prop tree.Attribute
 		k := attr.Name
 		v := attr.Value
 
+		if strings.HasPrefix(k, "@") {
+			continue
+		}
+
 		if strings.HasPrefix(k, ":") {
 			if scope != nil {
 				k = strings.Replace(k, ":", "", 1)
-				prop = newDynamicAttribute(k, v, scope)
+				prop = w.newDynamicAttribute(k, v, node, scope, instance)
 			} else {
 				log.Print("Instance was nil")
 			}
 		} else {
 			prop = newStaticAttribute(k, v)
+			if instance != nil {
+				w.setStaticProp(k, v, node, instance)
+			}
 		}
 
 		result = append(result, prop)
@@ -103,41 +220,146 @@ func (w *Walker) convertProperties(attrs []ast.Attribute, scope *scope.Scope) []
 	return result
 }
 
+// setStaticProp writes static attribute value in to the instance prop with the same name
+func (w *Walker) setStaticProp(k, v string, node ast.Node, instance *component.Wrapper) {
+	propName, isAProp := instance.IsAProp(k)
+	if !isAProp {
+		return
+	}
+
+	setter, ok := instance.Setter(propName)
+	if !ok {
+		return
+	}
+
+	if err := setter(v); err != nil {
+		w.report(&PropError{
+			TemplateID: w.templateID,
+			Pos:        node.Position(),
+			Component:  node.Tag(),
+			Prop:       k,
+			Err:        err,
+		})
+	}
+}
+
+// checkRequiredProps reports required instance props that are not set by the node attributes
+func (w *Walker) checkRequiredProps(node ast.Node, instance *component.Wrapper) {
+	set := make(map[string]bool)
+	for _, attr := range node.Attributes() {
+		if propName, ok := instance.IsAProp(strings.TrimPrefix(attr.Name, ":")); ok {
+			set[propName] = true
+		}
+	}
+
+	for _, prop := range instance.RequiredProps() {
+		if propName, _ := instance.IsAProp(prop); !set[propName] {
+			w.report(&MissingPropError{
+				TemplateID: w.templateID,
+				Pos:        node.Position(),
+				Component:  node.Tag(),
+				Prop:       prop,
+			})
+		}
+	}
+}
+
 func (w *Walker) walkComponent(nodes []ast.Node, parentScope *scope.Scope) []tree.Node {
 	cmps := make([]tree.Node, 0)
 
 	for _, astNode := range nodes {
 		var cmp tree.Node
+
+		if text, ok := astNode.(*ast.Text); ok {
+			cmps = append(cmps, w.newTextNode(text, parentScope))
+			continue
+		}
+
+		if cond, ok := astNode.(*ast.Conditional); ok {
+			cmps = append(cmps, w.newConditionalNode(cond, parentScope))
+			continue
+		}
+
+		if el, ok := astNode.(*ast.Element); ok && hasAttribute(el, directiveFor) {
+			cmps = append(cmps, w.newListNode(el, parentScope))
+			continue
+		}
+
+		if el, ok := astNode.(*ast.Element); ok && el.HTMLTag == slotTag {
+			cmps = append(cmps, w.newSlotNode(el, parentScope))
+			continue
+		}
+
 		tag := astNode.Tag()
-		instance, isComponent := registry.Instance(tag)
 		currScope := parentScope
 
-		if isComponent {
-			currScope = scope.New(instance, parentScope)
+		if registry.Exists(tag) && w.dry {
+			cmps = append(cmps, w.checkComponent(astNode, parentScope))
+			continue
+		}
 
-			templateID, ok := registry.TemplateID(tag)
-			if !ok {
-				log.Fatalf("Could not find template for %s", tag)
+		if registry.Exists(tag) {
+			instance, err := registry.Instance(tag)
+			if err != nil {
+				cmps = append(cmps, w.newFailedComponent(astNode, err, parentScope))
+				continue
 			}
 
-			innerWalker := NewByID(templateID)
-			ast := innerWalker.WalkAST(currScope)
-			w.errors = append(w.errors, innerWalker.Errors()...)
+			node := &tree.ComponentNode{NodeTag: tag, Instance: instance}
+			instance.SetNotifier(func() { w.notifyComponent(node) })
+			currScope = scope.New(instance, parentScope)
 
-			cmp = &tree.ComponentNode{
-				NodeTag:      tag,
-				NodeChildren: ast,
-				NodeBody:     w.walkComponent(astNode.Children(), currScope),
-				NodeProps:    w.convertProperties(astNode.Attributes(), currScope),
-				NodeHandlers: w.convertHandlers(astNode.Attributes(), currScope),
-				Instance:     instance,
+			// prop values belong to the parent component, instance only receives them
+			props := w.convertProperties(astNode, parentScope, instance)
+			w.checkRequiredProps(astNode, instance)
+			// handlers on the component tag belong to the parent component
+			handlers := w.convertHandlers(astNode, parentScope)
+			body, slots := w.walkBody(astNode.Children(), parentScope)
+			children := make([]tree.Node, 0)
+
+			templateID, ok := registry.TemplateID(tag)
+			if ok {
+				innerWalker := NewByID(templateID)
+				innerWalker.slots = slots
+				innerWalker.notify = w.notify
+				innerWalker.owner = node
+				children = innerWalker.WalkAST(currScope)
+				for _, err := range innerWalker.Errors() {
+					w.report(err)
+				}
+			} else {
+				w.fail(&MissingTemplateError{
+					TemplateID: w.templateID,
+					Pos:        astNode.Position(),
+					Component:  tag,
+				})
 			}
+
+			node.NodeChildren = children
+			node.NodeBody = body
+			node.NodeProps = props
+			node.NodeHandlers = handlers
+			instance.SetEmitHandler(func(name string, payload interface{}) {
+				node.Handle(name, &event.Event{Type: name, Detail: payload})
+			})
+			cmp = node
 		} else {
+			var modelProps []tree.Attribute
+			var modelHandlers []*tree.Handler
+			if el, ok := astNode.(*ast.Element); ok && hasAttribute(el, directiveModel) {
+				el, field := takeModel(el)
+				modelProps, modelHandlers = w.convertModel(el, field, currScope)
+				astNode = el
+			}
+
+			props := append(w.convertProperties(astNode, currScope, nil), modelProps...)
+			handlers := append(w.convertHandlers(astNode, currScope), modelHandlers...)
+
 			cmp = &tree.HTMLNode{
 				NodeTag:      tag,
 				NodeChildren: w.walkComponent(astNode.Children(), currScope),
-				NodeProps:    w.convertProperties(astNode.Attributes(), currScope),
-				NodeHandlers: w.convertHandlers(astNode.Attributes(), currScope),
+				NodeProps:    props,
+				NodeHandlers: handlers,
 			}
 		}
 
@@ -147,40 +369,121 @@ func (w *Walker) walkComponent(nodes []ast.Node, parentScope *scope.Scope) []tre
 	return cmps
 }
 
-func newDynamicAttribute(k, v string, scope *scope.Scope) tree.Attribute {
-	var f func() string
+// compileExpression compiles and checks expression against the scope,
+// returned function evaluates expression in the scope
+func (w *Walker) compileExpression(src string, pos ast.Position, scope *scope.Scope) func() interface{} {
+	e := w.compile(src, pos, scope)
 
-	getter, ok := scope.Getter(v)
+	return func() interface{} {
+		return evaluate(e, scope)
+	}
+}
+
+// compile compiles and checks expression against the scope,
+// returns nil and records an error if expression is not valid
+func (w *Walker) compile(src string, pos ast.Position, scope *scope.Scope) *expr.Expression {
+	e, err := expr.Compile(src)
+	if err == nil {
+		err = w.checkField(e, pos, scope)
+	}
+	if err == nil {
+		err = e.Check(scope)
+	}
+
+	if err != nil {
+		if _, ok := err.(*UnknownFieldError); !ok {
+			err = &ExpressionError{TemplateID: w.templateID, Pos: pos, Err: err}
+		}
+		w.fail(err)
+		return nil
+	}
+
+	return e
+}
+
+// checkField reports expression that is a single unknown identifier
+// as unknown field error with the template position
+func (w *Walker) checkField(e *expr.Expression, pos ast.Position, scope *scope.Scope) error {
+	name, ok := e.Identifier()
 	if !ok {
-		log.Fatalf("Could not find getter for %s", v)
-		f = func() string {
-			return v
-		}
-	} else {
-		f = func() string {
-			raw := getter()
-			s, ok := raw.(string)
-			if !ok {
-				log.Printf("Could not convert %v in to string", raw)
-				return fmt.Sprintf("%v", raw)
-			}
+		return nil
+	}
 
-			return s
+	if _, found := scope.FieldType(name); !found {
+		return &UnknownFieldError{
+			TemplateID: w.templateID,
+			Pos:        pos,
+			Field:      name,
 		}
 	}
 
-	propName, isAProp := scope.Wrapper.IsAProp(k)
+	return nil
+}
+
+func evaluate(e *expr.Expression, env expr.Env) interface{} {
+	if e == nil {
+		return nil
+	}
+
+	v, err := e.Eval(env)
+	if err != nil {
+		log.Printf("Could not evaluate expression: %s", err)
+		return nil
+	}
+
+	return v
+}
+
+func (w *Walker) newDynamicAttribute(k, v string, node ast.Node, scope *scope.Scope, instance *component.Wrapper) tree.Attribute {
+	eval := w.compileExpression(v, node.Position(), scope)
+	f := func() string {
+		return stringify(eval())
+	}
+
+	if instance == nil {
+		return &tree.DynamicAttribute{
+			K: k,
+			F: f,
+		}
+	}
+
+	propName, isAProp := instance.IsAProp(k)
 
 	if isAProp {
-		setter, ok := scope.Wrapper.Setter(propName)
+		setter, ok := instance.Setter(propName)
 		if !ok {
-			log.Fatalf("Could not find setter for %s with name %s", k, propName)
+			w.fail(&UnknownFieldError{
+				TemplateID: w.templateID,
+				Pos:        node.Position(),
+				Field:      propName,
+			})
+			return &tree.DynamicAttribute{
+				K: k,
+				F: f,
+			}
 		}
+		// failed holds the last reported value so the same error is not repeated on every update
+		var failed *string
 		return &tree.LinkedAttribute{
 			K: k,
 			F: f,
 			Sync: func() {
-				setter(f())
+				v := eval()
+				err := setter(v)
+				if err == nil {
+					failed = nil
+					return
+				}
+				if text := stringify(v); failed == nil || *failed != text {
+					failed = &text
+					w.report(&PropError{
+						TemplateID: w.templateID,
+						Pos:        node.Position(),
+						Component:  node.Tag(),
+						Prop:       k,
+						Err:        err,
+					})
+				}
 			},
 		}
 	}
@@ -191,9 +494,108 @@ func newDynamicAttribute(k, v string, scope *scope.Scope) tree.Attribute {
 	}
 }
 
+func (w *Walker) newConditionalNode(cond *ast.Conditional, scope *scope.Scope) tree.Node {
+	node := &tree.ConditionalNode{}
+
+	for _, branch := range cond.Branches {
+		astNode := branch.Node
+		treeBranch := &tree.ConditionalBranch{
+			Build: func() []tree.Node {
+				return w.walkChecked([]ast.Node{astNode}, scope)
+			},
+		}
+		w.check([]ast.Node{astNode}, scope)
+
+		if branch.Condition != "" {
+			eval := w.compileExpression(branch.Condition, astNode.Position(), scope)
+			treeBranch.Condition = func() bool {
+				return expr.Truthy(eval())
+			}
+		}
+
+		node.Branches = append(node.Branches, treeBranch)
+	}
+
+	return node
+}
+
 func newStaticAttribute(k, v string) tree.Attribute {
 	return &tree.StaticAttribute{
 		K: k,
 		V: v,
 	}
 }
+
+const (
+	interpolationOpen  = "{{"
+	interpolationClose = "}}"
+)
+
+// newTextNode converts text content in to text node,
+// text with {{ Expression }} interpolations becomes dynamic text node
+// that evaluates every expression in the scope
+func (w *Walker) newTextNode(text *ast.Text, scope *scope.Scope) tree.Node {
+	content := text.Content
+
+	if !strings.Contains(content, interpolationOpen) {
+		return &tree.TextNode{NodeText: content}
+	}
+
+	parts := make([]func() string, 0)
+	rest := content
+
+	for {
+		start := strings.Index(rest, interpolationOpen)
+		if start == -1 {
+			break
+		}
+
+		end := strings.Index(rest[start:], interpolationClose)
+		if end == -1 {
+			break
+		}
+		end += start
+
+		if start > 0 {
+			parts = append(parts, staticPart(rest[:start]))
+		}
+
+		eval := w.compileExpression(strings.TrimSpace(rest[start+len(interpolationOpen):end]), text.Pos, scope)
+		parts = append(parts, func() string {
+			return stringify(eval())
+		})
+
+		rest = rest[end+len(interpolationClose):]
+	}
+
+	if rest != "" {
+		parts = append(parts, staticPart(rest))
+	}
+
+	return &tree.DynamicTextNode{
+		F: func() string {
+			var out strings.Builder
+			for _, part := range parts {
+				out.WriteString(part())
+			}
+			return out.String()
+		},
+	}
+}
+
+func staticPart(s string) func() string {
+	return func() string { return s }
+}
+
+func stringify(raw interface{}) string {
+	if raw == nil {
+		return ""
+	}
+
+	s, ok := raw.(string)
+	if !ok {
+		return fmt.Sprintf("%v", raw)
+	}
+
+	return s
+}
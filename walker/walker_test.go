@@ -3,12 +3,15 @@ package walker
 import (
 	"testing"
 
+	"github.com/Gonzih/wasm-mk2/ast"
 	"github.com/Gonzih/wasm-mk2/component"
 	"github.com/Gonzih/wasm-mk2/dom"
 	"github.com/Gonzih/wasm-mk2/event"
 	"github.com/Gonzih/wasm-mk2/registry"
+	"github.com/Gonzih/wasm-mk2/render"
 	"github.com/Gonzih/wasm-mk2/scope"
 	"github.com/Gonzih/wasm-mk2/tree"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,8 +22,9 @@ type EmptyDiv struct {
 func (c *EmptyDiv) Init() error { return nil }
 
 type MyDiv struct {
-	Input   string `wasm:"prop"`
-	Counter int    `wasm:"state"`
+	_       struct{} `wasm:"computed=StatusClass|IsBig"`
+	Input   string   `wasm:"prop"`
+	Counter int      `wasm:"state"`
 }
 
 func (c *MyDiv) Init() error {
@@ -33,6 +37,10 @@ func (c *MyDiv) HandleClick(e *event.Event) {
 	c.Counter += 6
 }
 
+func (c *MyDiv) IsBig() bool {
+	return c.Counter > 15
+}
+
 func walkString(t *testing.T, input string) *Walker {
 	dom.RegisterMockTemplate("app-root", input)
 	w := NewByID("app-root")
@@ -76,6 +84,21 @@ func TestNested(t *testing.T) {
 	require.Equal(t, "a", cmp[0].Children()[1].Tag())
 }
 
+func TestText(t *testing.T) {
+	input := `<p>Hello, <b>world</b></p>`
+	w := walkString(t, input)
+	cmp := w.WalkAST(scope.Empty())
+	checkWalkErrors(t, w)
+
+	require.Len(t, cmp, 1)
+	require.Len(t, cmp[0].Children(), 2)
+
+	text, ok := cmp[0].Children()[0].(*tree.TextNode)
+	require.True(t, ok)
+	require.Equal(t, "Hello, ", text.Text())
+	require.Equal(t, "b", cmp[0].Children()[1].Tag())
+}
+
 func TestSimpleComponent(t *testing.T) {
 	wrapper, err := component.Wasmify(&MyDiv{})
 	require.Nil(t, err)
@@ -120,9 +143,12 @@ func TestSimpleComponentWithDynamicProp(t *testing.T) {
 	registry.RegisterTemplate("mydiv", "mydiv-template")
 	dom.RegisterMockTemplate("mydiv-template", `<div></div>`)
 
+	parent, err := wrapper.Instance()
+	require.Nil(t, err)
+
 	input := `<mydiv :id="Input"></mydiv>`
 	w := walkString(t, input)
-	cmp := w.WalkAST(scope.Empty())
+	cmp := w.WalkAST(scope.New(parent, scope.Empty()))
 	checkWalkErrors(t, w)
 
 	require.Len(t, cmp, 1)
@@ -143,9 +169,12 @@ func TestSimpleComponentWithDynamicPropAndNestedScopes(t *testing.T) {
 	registry.RegisterTemplate("empty-div", "empty-div-template")
 	dom.RegisterMockTemplate("empty-div-template", `<div></div>`)
 
+	parent, err := registry.Instance("mydiv")
+	require.Nil(t, err)
+
 	input := `<mydiv><empty-div :class="Input"></empty-div></mydiv>`
 	w := walkString(t, input)
-	cmp := w.WalkAST(scope.Empty())
+	cmp := w.WalkAST(scope.New(parent, scope.Empty()))
 	checkWalkErrors(t, w)
 
 	require.Len(t, cmp, 1)
@@ -166,10 +195,13 @@ func TestSimpleComponentWithDynamicPropPassing(t *testing.T) {
 	registry.RegisterTemplate("empty-div", "empty-div-template")
 	dom.RegisterMockTemplate("empty-div-template", `<div></div>`)
 
+	parent, err := registry.Instance("mydiv")
+	require.Nil(t, err)
+
 	input := `<mydiv><empty-div :data="Input"></empty-div></mydiv>`
 	dom.RegisterMockTemplate("app-root", input)
 	w := NewByID("app-root")
-	cmp := w.WalkAST(scope.Empty())
+	cmp := w.WalkAST(scope.New(parent, scope.Empty()))
 	checkWalkErrors(t, w)
 
 	node := cmp[0].Body()[0]
@@ -224,3 +256,831 @@ func TestHandlersBasic(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, 17, getter())
 }
+
+func TestInterpolation(t *testing.T) {
+	wrapper, err := component.Wasmify(&MyDiv{})
+	require.Nil(t, err)
+	registry.Register("mydiv", wrapper)
+	registry.RegisterTemplate("mydiv", "mydiv-template")
+	dom.RegisterMockTemplate("mydiv-template", `<span @click="HandleClick">Count: {{ Counter }} of {{Input}}</span>`)
+
+	input := `<mydiv></mydiv>`
+	w := walkString(t, input)
+	cmp := w.WalkAST(scope.Empty())
+	checkWalkErrors(t, w)
+
+	span := cmp[0].Children()[0]
+	text, ok := span.Children()[0].(*tree.DynamicTextNode)
+	require.True(t, ok)
+	require.Equal(t, "Count: 11 of MyDynamicInput", text.Text())
+
+	require.True(t, span.Handle("click", &event.Event{}))
+	cmp[0].Notify()
+	require.Equal(t, "Count: 17 of MyDynamicInput", text.Text())
+}
+
+func TestInterpolationOfLinkedPropInNestedComponent(t *testing.T) {
+	wrapper, err := component.Wasmify(&MyDiv{})
+	require.Nil(t, err)
+	registry.Register("mydiv", wrapper)
+	registry.RegisterTemplate("mydiv", "mydiv-template")
+	dom.RegisterMockTemplate("mydiv-template", `<div @click="HandleClick"><empty-div :data="Counter"></empty-div></div>`)
+
+	wrapper, err = component.Wasmify(&EmptyDiv{})
+	require.Nil(t, err)
+	registry.Register("empty-div", wrapper)
+	registry.RegisterTemplate("empty-div", "empty-div-template")
+	dom.RegisterMockTemplate("empty-div-template", `<p>{{ Data }}</p>`)
+
+	input := `<mydiv></mydiv>`
+	w := walkString(t, input)
+	cmp := w.WalkAST(scope.Empty())
+	checkWalkErrors(t, w)
+
+	div := cmp[0].Children()[0]
+	text := div.Children()[0].Children()[0].Children()[0].(*tree.DynamicTextNode)
+	require.Equal(t, "11", text.Text())
+
+	require.True(t, div.Handle("click", &event.Event{}))
+	cmp[0].Notify()
+	require.Equal(t, "17", text.Text())
+}
+
+func TestExpressions(t *testing.T) {
+	wrapper, err := component.Wasmify(&MyDiv{})
+	require.Nil(t, err)
+	registry.Register("mydiv", wrapper)
+	registry.RegisterTemplate("mydiv", "mydiv-template")
+	dom.RegisterMockTemplate("mydiv-template", `<p @click="HandleClick" :class="IsBig() ? 'big' : 'small'">{{ !IsBig() && Counter != 0 }}</p>`)
+
+	input := `<mydiv></mydiv>`
+	w := walkString(t, input)
+	cmp := w.WalkAST(scope.Empty())
+	checkWalkErrors(t, w)
+
+	p := cmp[0].Children()[0]
+	text := p.Children()[0].(*tree.DynamicTextNode)
+	require.Equal(t, "small", p.Props()[0].Value())
+	require.Equal(t, "true", text.Text())
+
+	require.True(t, p.Handle("click", &event.Event{}))
+	require.Equal(t, "big", p.Props()[0].Value())
+	require.Equal(t, "false", text.Text())
+}
+
+func TestExpressionErrors(t *testing.T) {
+	wrapper, err := component.Wasmify(&MyDiv{})
+	require.Nil(t, err)
+	registry.Register("mydiv", wrapper)
+	registry.RegisterTemplate("mydiv", "mydiv-template")
+	dom.RegisterMockTemplate("mydiv-template", `<p :class="Missing">{{ Counter == "a" }}</p>`)
+
+	dom.RegisterMockTemplate("app-root", `<mydiv></mydiv>`)
+	w := NewByID("app-root")
+	w.WalkAST(scope.Empty())
+
+	require.Len(t, w.Errors(), 2)
+	require.Contains(t, w.Errors()[0].Error(), "unknown field Missing")
+	require.Contains(t, w.Errors()[1].Error(), "mismatched types int and string")
+}
+
+func TestConditionals(t *testing.T) {
+	wrapper, err := component.Wasmify(&MyDiv{})
+	require.Nil(t, err)
+	registry.Register("mydiv", wrapper)
+	registry.RegisterTemplate("mydiv", "mydiv-template")
+	dom.RegisterMockTemplate("mydiv-template", `
+	<div @click="HandleClick">
+		<p w-if="Counter < 15">small</p>
+		<p w-else-if="Counter < 20">medium</p>
+		<empty-div w-else :data="Counter"></empty-div>
+	</div>`)
+
+	wrapper, err = component.Wasmify(&EmptyDiv{})
+	require.Nil(t, err)
+	registry.Register("empty-div", wrapper)
+	registry.RegisterTemplate("empty-div", "empty-div-template")
+	dom.RegisterMockTemplate("empty-div-template", `<b>{{ Data }}</b>`)
+
+	w := walkString(t, `<mydiv></mydiv>`)
+	cmp := w.WalkAST(scope.Empty())
+	checkWalkErrors(t, w)
+
+	div := cmp[0].Children()[0]
+	cond, ok := div.Children()[0].(*tree.ConditionalNode)
+	require.True(t, ok)
+	require.Len(t, cond.Children(), 1)
+	require.Equal(t, "small", cond.Children()[0].Children()[0].(*tree.TextNode).Text())
+
+	div.Handle("click", &event.Event{})
+	cmp[0].Notify()
+	require.Equal(t, "medium", cond.Children()[0].Children()[0].(*tree.TextNode).Text())
+
+	div.Handle("click", &event.Event{})
+	cmp[0].Notify()
+	child, ok := cond.Children()[0].(*tree.ComponentNode)
+	require.True(t, ok)
+	require.Equal(t, "23", child.Children()[0].Children()[0].(*tree.DynamicTextNode).Text())
+
+	cmp[0].Notify()
+	require.Equal(t, child, cond.Children()[0])
+	checkWalkErrors(t, w)
+}
+
+func TestConditionalTearsDownComponents(t *testing.T) {
+	wrapper, err := component.Wasmify(&MyDiv{})
+	require.Nil(t, err)
+	registry.Register("mydiv", wrapper)
+	registry.RegisterTemplate("mydiv", "mydiv-template")
+	dom.RegisterMockTemplate("mydiv-template", `<div @click="HandleClick"><empty-div w-if="Counter != 17"></empty-div></div>`)
+
+	wrapper, err = component.Wasmify(&EmptyDiv{})
+	require.Nil(t, err)
+	registry.Register("empty-div", wrapper)
+	registry.RegisterTemplate("empty-div", "empty-div-template")
+	dom.RegisterMockTemplate("empty-div-template", `<b></b>`)
+
+	w := walkString(t, `<mydiv></mydiv>`)
+	cmp := w.WalkAST(scope.Empty())
+
+	div := cmp[0].Children()[0]
+	cond := div.Children()[0]
+	first := cond.Children()[0].(*tree.ComponentNode).Instance
+
+	div.Handle("click", &event.Event{})
+	cmp[0].Notify()
+	require.Len(t, cond.Children(), 0)
+
+	div.Handle("click", &event.Event{})
+	cmp[0].Notify()
+	require.Len(t, cond.Children(), 1)
+	require.NotEqual(t, first.UUID(), cond.Children()[0].(*tree.ComponentNode).Instance.UUID())
+}
+
+type Todo struct {
+	ID    int
+	Title string
+}
+
+type TodoList struct {
+	Todos []Todo         `wasm:"state"`
+	Tags  map[string]int `wasm:"state"`
+}
+
+func (c *TodoList) Init() error {
+	c.Todos = []Todo{{1, "first"}, {2, "second"}, {3, "third"}}
+	c.Tags = map[string]int{"b": 2, "a": 1}
+	return nil
+}
+
+func registerTodoList(t *testing.T, template string) {
+	wrapper, err := component.Wasmify(&TodoList{})
+	require.Nil(t, err)
+	registry.Register("todo-list", wrapper)
+	registry.RegisterTemplate("todo-list", "todo-list-template")
+	dom.RegisterMockTemplate("todo-list-template", template)
+}
+
+func listTexts(node tree.Node) []string {
+	texts := make([]string, 0)
+	for _, ch := range node.Children() {
+		texts = append(texts, ch.Children()[0].(*tree.DynamicTextNode).Text())
+	}
+
+	return texts
+}
+
+func TestList(t *testing.T) {
+	registerTodoList(t, `<ul><li w-for="todo, i in Todos" :key="todo.ID" :data-index="i">{{ todo.Title }}</li></ul>`)
+
+	w := walkString(t, `<todo-list></todo-list>`)
+	cmp := w.WalkAST(scope.Empty())
+	checkWalkErrors(t, w)
+
+	list, ok := cmp[0].Children()[0].Children()[0].(*tree.ListNode)
+	require.True(t, ok)
+	require.Equal(t, []string{"first", "second", "third"}, listTexts(list))
+	require.Len(t, list.Children()[0].Props(), 1)
+	require.Equal(t, "data-index", list.Children()[0].Props()[0].Key())
+
+	second := list.Children()[1]
+	require.Equal(t, "1", second.Props()[0].Value())
+
+	todos := cmp[0].(*tree.ComponentNode).Instance
+	setter, ok := todos.Setter("Todos")
+	require.True(t, ok)
+	require.Nil(t, setter([]Todo{{4, "fourth"}, {2, "second!"}, {1, "first"}}))
+	cmp[0].Notify()
+
+	require.Equal(t, []string{"fourth", "second!", "first"}, listTexts(list))
+	require.Equal(t, second, list.Children()[1])
+	require.Equal(t, "1", second.Props()[0].Value())
+	require.Equal(t, "2", list.Children()[2].Props()[0].Value())
+
+	keys := make([]string, 0)
+	for _, entry := range list.Entries() {
+		keys = append(keys, entry.Key)
+	}
+	require.Equal(t, []string{"4", "2", "1"}, keys)
+}
+
+func TestListOverMap(t *testing.T) {
+	registerTodoList(t, `<ul><li w-for="(count, name) in Tags">{{ name }}={{ count }}</li></ul>`)
+
+	w := walkString(t, `<todo-list></todo-list>`)
+	cmp := w.WalkAST(scope.Empty())
+	checkWalkErrors(t, w)
+
+	list := cmp[0].Children()[0].Children()[0]
+	require.Equal(t, []string{"a=1", "b=2"}, listTexts(list))
+}
+
+func TestListOfComponents(t *testing.T) {
+	registerTodoList(t, `<div><empty-div w-for="todo in Todos" :key="todo.ID" :data="todo.Title" w-if="todo.ID != 2"></empty-div></div>`)
+
+	wrapper, err := component.Wasmify(&EmptyDiv{})
+	require.Nil(t, err)
+	registry.Register("empty-div", wrapper)
+	registry.RegisterTemplate("empty-div", "empty-div-template")
+	dom.RegisterMockTemplate("empty-div-template", `<b>{{ Data }}</b>`)
+
+	w := walkString(t, `<todo-list></todo-list>`)
+	cmp := w.WalkAST(scope.Empty())
+	checkWalkErrors(t, w)
+
+	list := cmp[0].Children()[0].Children()[0]
+	require.Len(t, list.Children(), 3)
+	require.Len(t, list.Children()[0].Children(), 1)
+	require.Len(t, list.Children()[1].Children(), 0)
+	require.Len(t, list.Children()[2].Children(), 1)
+
+	getter, ok := list.Children()[2].Children()[0].(*tree.ComponentNode).Instance.Getter("Data")
+	require.True(t, ok)
+	require.Equal(t, "third", getter())
+}
+
+func TestListErrors(t *testing.T) {
+	registerTodoList(t, `<ul><li w-for="todo of Todos"></li><li w-for="todo in Missing"></li><li w-for="todo in Todos" :key="todo.ID ==">{{ todo.Name }}</li></ul>`)
+
+	dom.RegisterMockTemplate("app-root", `<todo-list></todo-list>`)
+	w := NewByID("app-root")
+	w.WalkAST(scope.Empty())
+
+	require.Len(t, w.Errors(), 4)
+	require.Contains(t, w.Errors()[0].Error(), "invalid w-for expression")
+	require.Contains(t, w.Errors()[1].Error(), "unknown field Missing")
+	require.Contains(t, w.Errors()[2].Error(), "unexpected end of expression")
+	require.Contains(t, w.Errors()[3].Error(), "has no field Name")
+}
+
+type Card struct {
+	Title string `wasm:"prop"`
+}
+
+func (c *Card) Init() error { return nil }
+
+func TestSlots(t *testing.T) {
+	wrapper, err := component.Wasmify(&Card{})
+	require.Nil(t, err)
+	registry.Register("card", wrapper)
+	registry.RegisterTemplate("card", "card-template")
+	dom.RegisterMockTemplate("card-template", `
+	<div class="card">
+		<header><slot name="title">Untitled</slot></header>
+		<slot></slot>
+		<footer><slot name="footer"><i>none</i></slot></footer>
+	</div>`)
+
+	wrapper, err = component.Wasmify(&MyDiv{})
+	require.Nil(t, err)
+	registry.Register("mydiv", wrapper)
+	registry.RegisterTemplate("mydiv", "mydiv-template")
+	dom.RegisterMockTemplate("mydiv-template", `
+	<card @click="HandleClick">
+		<h1 slot="title" class="title">{{ Counter }}</h1>
+		<p>body</p>
+		text
+	</card>`)
+
+	w := walkString(t, `<mydiv></mydiv>`)
+	cmp := w.WalkAST(scope.Empty())
+	checkWalkErrors(t, w)
+
+	card := cmp[0].Children()[0]
+	require.Len(t, card.Body(), 3)
+
+	div := card.Children()[0]
+	header := div.Children()[0]
+	title, ok := header.Children()[0].(*tree.SlotNode)
+	require.True(t, ok)
+	require.False(t, title.Fallback)
+	require.Len(t, title.Children(), 1)
+
+	h1 := title.Children()[0]
+	require.Equal(t, "h1", h1.Tag())
+	require.Len(t, h1.Props(), 1)
+	require.Equal(t, "class", h1.Props()[0].Key())
+	require.Equal(t, "11", h1.Children()[0].(*tree.DynamicTextNode).Text())
+
+	body := div.Children()[1].(*tree.SlotNode)
+	require.Len(t, body.Children(), 2)
+	require.Equal(t, "p", body.Children()[0].Tag())
+	require.Equal(t, " text ", body.Children()[1].(*tree.TextNode).Text())
+
+	footer := div.Children()[2].Children()[0].(*tree.SlotNode)
+	require.True(t, footer.Fallback)
+	require.Equal(t, "i", footer.Children()[0].Tag())
+
+	require.True(t, card.Handle("click", &event.Event{}))
+	cmp[0].Notify()
+	require.Equal(t, "17", h1.Children()[0].(*tree.DynamicTextNode).Text())
+}
+
+func TestTypedErrors(t *testing.T) {
+	wrapper, err := component.Wasmify(&MyDiv{})
+	require.Nil(t, err)
+	registry.Register("mydiv", wrapper)
+	registry.RegisterTemplate("mydiv", "mydiv-template")
+	dom.RegisterMockTemplate("mydiv-template", "<div>\n  <p @click=\"HandleTypo\">{{ Countr }}</p>\n</div>")
+
+	wrapper, err = component.Wasmify(&EmptyDiv{})
+	require.Nil(t, err)
+	registry.Register("untemplated-div", wrapper)
+
+	dom.RegisterMockTemplate("app-root", `<mydiv></mydiv><untemplated-div></untemplated-div>`)
+	w := NewByID("app-root")
+	cmp := w.WalkAST(scope.Empty())
+	require.Len(t, cmp, 2)
+	require.Len(t, w.Errors(), 3)
+
+	handlerErr, ok := w.Errors()[0].(*UnknownHandlerError)
+	require.True(t, ok)
+	require.Equal(t, "mydiv-template", handlerErr.TemplateID)
+	require.Equal(t, ast.Position{Line: 2, Column: 3}, handlerErr.Pos)
+	require.Equal(t, "click", handlerErr.Event)
+	require.Equal(t, "HandleTypo", handlerErr.Handler)
+	require.Equal(t, "mydiv-template:2:3: unknown handler HandleTypo for @click", handlerErr.Error())
+
+	fieldErr, ok := w.Errors()[1].(*UnknownFieldError)
+	require.True(t, ok)
+	require.Equal(t, ast.Position{Line: 2, Column: 26}, fieldErr.Pos)
+	require.Equal(t, "Countr", fieldErr.Field)
+
+	templateErr, ok := w.Errors()[2].(*MissingTemplateError)
+	require.True(t, ok)
+	require.Equal(t, "app-root:1:16: missing template for component <untemplated-div>", templateErr.Error())
+	require.Len(t, cmp[1].Children(), 0)
+}
+
+type Broken struct {
+	Data string `wasm:"prop"`
+}
+
+var errBroken = errors.New("broken")
+
+func (c *Broken) Init() error { return errBroken }
+
+func TestInitErrors(t *testing.T) {
+	wrapper, err := component.Wasmify(&Broken{})
+	require.Nil(t, err)
+	registry.Register("broken", wrapper)
+	registry.RegisterTemplate("broken", "broken-template")
+	dom.RegisterMockTemplate("broken-template", `<div>never</div>`)
+
+	dom.RegisterMockTemplate("app-root", "<div>\n<broken :data=\"Missing\"><p>body</p></broken></div>")
+	w := NewByID("app-root")
+	cmp := w.WalkAST(scope.Empty())
+
+	require.Len(t, w.Errors(), 1)
+	initErr, ok := w.Errors()[0].(*InitError)
+	require.True(t, ok)
+	require.Equal(t, "broken", initErr.Component)
+	require.Equal(t, ast.Position{Line: 2, Column: 1}, initErr.Pos)
+	require.Equal(t, errBroken, errors.Cause(initErr.Err))
+
+	failed := cmp[0].Children()[0].(*tree.ComponentNode)
+	require.Nil(t, failed.Instance)
+	require.Len(t, failed.Children(), 0)
+}
+
+func TestInitFallback(t *testing.T) {
+	wrapper, err := component.Wasmify(&Broken{})
+	require.Nil(t, err)
+	registry.Register("broken", wrapper)
+	registry.RegisterTemplate("broken", "broken-template")
+	dom.RegisterMockTemplate("broken-template", `<div>never</div>`)
+
+	wrapper, err = component.Wasmify(&MyDiv{})
+	require.Nil(t, err)
+	registry.Register("mydiv", wrapper)
+	registry.RegisterTemplate("mydiv", "mydiv-template")
+	dom.RegisterMockTemplate("mydiv-template", `<broken><p w-fallback class="error">failed {{ Counter }}</p><p>body</p></broken><empty-div><i w-fallback>unused</i>ok</empty-div>`)
+
+	wrapper, err = component.Wasmify(&EmptyDiv{})
+	require.Nil(t, err)
+	registry.Register("empty-div", wrapper)
+	registry.RegisterTemplate("empty-div", "empty-div-template")
+	dom.RegisterMockTemplate("empty-div-template", `<div><slot></slot></div>`)
+
+	w := walkString(t, `<mydiv></mydiv>`)
+	cmp := w.WalkAST(scope.Empty())
+	checkWalkErrors(t, w)
+
+	html, err := render.String(cmp)
+	require.Nil(t, err)
+	require.Equal(t, `<p class="error">failed 11</p><div>ok</div>`, html)
+}
+
+var tracked []string
+
+type Tracked struct {
+	Data string `wasm:"prop"`
+}
+
+func (c *Tracked) Init() error { return nil }
+func (c *Tracked) Mounted()    { tracked = append(tracked, "mounted "+c.Data) }
+func (c *Tracked) Unmounted()  { tracked = append(tracked, "unmounted "+c.Data) }
+
+func TestListLifecycle(t *testing.T) {
+	tracked = nil
+	registerTodoList(t, `<div><tracked w-for="todo in Todos" :key="todo.ID" :data="todo.Title"></tracked></div>`)
+
+	wrapper, err := component.Wasmify(&Tracked{})
+	require.Nil(t, err)
+	registry.Register("tracked", wrapper)
+	registry.RegisterTemplate("tracked", "tracked-template")
+	dom.RegisterMockTemplate("tracked-template", `<span></span>`)
+
+	w := walkString(t, `<todo-list></todo-list>`)
+	cmp := w.WalkAST(scope.Empty())
+	checkWalkErrors(t, w)
+	require.Len(t, tracked, 0)
+
+	tree.Mount(cmp)
+	require.Equal(t, []string{"mounted first", "mounted second", "mounted third"}, tracked)
+
+	tracked = nil
+	setter, _ := cmp[0].(*tree.ComponentNode).Instance.Setter("Todos")
+	require.Nil(t, setter([]Todo{{2, "second"}, {4, "fourth"}}))
+	cmp[0].Notify()
+	require.ElementsMatch(t, []string{"unmounted first", "unmounted third", "mounted fourth"}, tracked)
+
+	tracked = nil
+	tree.Unmount(cmp)
+	require.Equal(t, []string{"unmounted second", "unmounted fourth"}, tracked)
+}
+
+func (c *MyDiv) StatusClass() string {
+	if c.Counter > 15 {
+		return "status-big"
+	}
+	return "status-small"
+}
+
+func TestComputedGetters(t *testing.T) {
+	wrapper, err := component.Wasmify(&MyDiv{})
+	require.Nil(t, err)
+	registry.Register("mydiv", wrapper)
+	registry.RegisterTemplate("mydiv", "mydiv-template")
+	dom.RegisterMockTemplate("mydiv-template", `<p @click="HandleClick" :class="StatusClass">{{ StatusClass }} {{ IsBig }}</p>`)
+
+	w := walkString(t, `<mydiv></mydiv>`)
+	cmp := w.WalkAST(scope.Empty())
+	checkWalkErrors(t, w)
+
+	html, err := render.String(cmp)
+	require.Nil(t, err)
+	require.Equal(t, `<p class="status-small">status-small false</p>`, html)
+
+	cmp[0].Children()[0].Handle("click", &event.Event{})
+	html, err = render.String(cmp)
+	require.Nil(t, err)
+	require.Equal(t, `<p class="status-big">status-big true</p>`, html)
+}
+
+type Recorder struct {
+	Items []string `wasm:"state"`
+	Calls []string
+}
+
+func (c *Recorder) Init() error {
+	c.Items = []string{"a", "b"}
+	return nil
+}
+
+func (c *Recorder) record(name string) { c.Calls = append(c.Calls, name) }
+
+func (c *Recorder) HandleCapture(e *event.Event) { c.record("capture") }
+func (c *Recorder) HandleOuter(e *event.Event)   { c.record("outer") }
+func (c *Recorder) HandleRow(e *event.Event)     { c.record("row") }
+func (c *Recorder) HandleInner(e *event.Event)   { c.record("inner") }
+
+func TestDispatch(t *testing.T) {
+	wrapper, err := component.Wasmify(&Recorder{})
+	require.Nil(t, err)
+	registry.Register("recorder", wrapper)
+	registry.RegisterTemplate("recorder", "recorder-template")
+	dom.RegisterMockTemplate("recorder-template", `<div @click.capture="HandleCapture" @click="HandleOuter"><ul @click="HandleRow"><li w-for="item in Items"><b @click="HandleInner">{{ item }}</b><i @click.stop="HandleInner">{{ item }}</i></li></ul></div>`)
+
+	wrapper, err = component.Wasmify(&MyDiv{})
+	require.Nil(t, err)
+	registry.Register("mydiv", wrapper)
+	registry.RegisterTemplate("mydiv", "mydiv-template")
+	dom.RegisterMockTemplate("mydiv-template", `<recorder @click="HandleClick"></recorder>`)
+
+	w := walkString(t, `<mydiv></mydiv>`)
+	cmp := w.WalkAST(scope.Empty())
+	checkWalkErrors(t, w)
+
+	rec := cmp[0].Children()[0].(*tree.ComponentNode)
+	require.Equal(t, cmp[0], rec.Parent())
+	calls, _ := rec.Instance.Getter("Calls")
+	counter, _ := cmp[0].(*tree.ComponentNode).Instance.Getter("Counter")
+
+	list := rec.Children()[0].Children()[0].Children()[0].(*tree.ListNode)
+	li := list.Children()[1]
+	require.Equal(t, list, li.Parent())
+
+	text := li.Children()[0].Children()[0]
+	tree.Dispatch(text, "click", &event.Event{}, nil)
+	require.Equal(t, []string{"capture", "inner", "row", "outer"}, calls())
+	// native events do not reach handlers of the component tag
+	require.Equal(t, 11, counter())
+
+	tree.Dispatch(li.Children()[1], "click", &event.Event{}, nil)
+	require.Equal(t, []string{"capture", "inner", "row", "outer", "capture", "inner"}, calls())
+	require.Equal(t, 11, counter())
+}
+
+func TestUnknownModifier(t *testing.T) {
+	dom.RegisterMockTemplate("app-root", `<p @click.once="HandleClick"></p>`)
+	wrapper, err := component.Wasmify(&MyDiv{})
+	require.Nil(t, err)
+	instance, err := wrapper.Instance()
+	require.Nil(t, err)
+
+	w := NewByID("app-root")
+	w.WalkAST(scope.New(instance, scope.Empty()))
+	require.Len(t, w.Errors(), 1)
+	require.Equal(t, "app-root:1:1: unknown modifier .once for @click", w.Errors()[0].Error())
+}
+
+func TestModelErrors(t *testing.T) {
+	registerTodoList(t, `<ul><input w-model="Todos[0]"><input w-model="todo" w-for="todo in Todos"><select multiple w-model="Todos"></select></ul>`)
+
+	dom.RegisterMockTemplate("app-root", `<todo-list></todo-list>`)
+	w := NewByID("app-root")
+	w.WalkAST(scope.Empty())
+
+	require.Len(t, w.Errors(), 3)
+	modelErr, ok := w.Errors()[0].(*ModelError)
+	require.True(t, ok)
+	require.Equal(t, "todo-list-template:1:5: w-model requires a field name, got Todos[0]", modelErr.Error())
+	require.Contains(t, w.Errors()[1].Error(), "w-model field todo could not be set")
+	require.Contains(t, w.Errors()[2].Error(), "w-model does not support select with multiple attribute")
+}
+
+type Countdown struct {
+	Start int `wasm:"prop"`
+}
+
+func (c *Countdown) Init() error { return nil }
+
+type Launcher struct {
+	Text   string
+	Number string
+}
+
+func (c *Launcher) Init() error {
+	c.Text = "five"
+	c.Number = "5"
+	return nil
+}
+
+func registerCountdown(t *testing.T) {
+	wrapper, err := component.Wasmify(&Countdown{})
+	require.Nil(t, err)
+	registry.Register("count-down", wrapper)
+	registry.RegisterTemplate("count-down", "count-down-template")
+	dom.RegisterMockTemplate("count-down-template", `<p>{{ Start }}</p>`)
+}
+
+func TestPropErrors(t *testing.T) {
+	registerCountdown(t)
+	wrapper, err := component.Wasmify(&Launcher{})
+	require.Nil(t, err)
+	registry.Register("launcher", wrapper)
+	registry.RegisterTemplate("launcher", "launcher-template")
+	dom.RegisterMockTemplate("launcher-template", `<count-down :start="Text"></count-down><div><count-down :start="Number"></count-down></div>`)
+
+	dom.RegisterMockTemplate("app-root", `<launcher></launcher>`)
+	w := NewByID("app-root")
+	cmp := w.WalkAST(scope.Empty())
+
+	require.Len(t, w.Errors(), 1)
+	propErr, ok := w.Errors()[0].(*PropError)
+	require.True(t, ok)
+	require.Equal(t, "count-down", propErr.Component)
+	require.Equal(t, "start", propErr.Prop)
+	require.Equal(t, `launcher-template:1:1: could not set prop start of <count-down>: Could not convert "five" to int: strconv.ParseInt: parsing "five": invalid syntax`, propErr.Error())
+
+	html, err := render.String(cmp)
+	require.Nil(t, err)
+	require.Equal(t, "<p>0</p><div><p>5</p></div>", html)
+}
+
+func TestStaticProps(t *testing.T) {
+	registerCountdown(t)
+	wrapper, err := component.Wasmify(&EmptyDiv{})
+	require.Nil(t, err)
+	registry.Register("empty-div", wrapper)
+	registry.RegisterTemplate("empty-div", "empty-div-template")
+	dom.RegisterMockTemplate("empty-div-template", `<span>{{ Data }}</span>`)
+
+	dom.RegisterMockTemplate("app-root", `<div><empty-div data="hello" title="ignored"></empty-div><count-down start="3"></count-down><count-down start="x"></count-down></div>`)
+	w := NewByID("app-root")
+	cmp := w.WalkAST(scope.Empty())
+
+	require.Len(t, w.Errors(), 1)
+	require.Contains(t, w.Errors()[0].Error(), `app-root:1:93: could not set prop start of <count-down>: Could not convert "x" to int`)
+
+	html, err := render.String(cmp)
+	require.Nil(t, err)
+	require.Equal(t, "<div><span>hello</span><p>3</p><p>0</p></div>", html)
+}
+
+type Badge struct {
+	Label string `wasm:"prop,required"`
+	Tone  string `wasm:"prop,default=info,oneof=info|warning"`
+}
+
+func (c *Badge) Init() error { return nil }
+
+func TestPropValidation(t *testing.T) {
+	wrapper, err := component.Wasmify(&Badge{})
+	require.Nil(t, err)
+	registry.Register("ui-badge", wrapper)
+	registry.RegisterTemplate("ui-badge", "ui-badge-template")
+	dom.RegisterMockTemplate("ui-badge-template", `<span>{{ Tone }}: {{ Label }}</span>`)
+
+	dom.RegisterMockTemplate("app-root", `<div><ui-badge label="ok"></ui-badge><ui-badge tone="error"></ui-badge></div>`)
+	w := NewByID("app-root")
+	cmp := w.WalkAST(scope.Empty())
+
+	require.Len(t, w.Errors(), 2)
+	propErr, ok := w.Errors()[0].(*PropError)
+	require.True(t, ok)
+	require.Equal(t, `app-root:1:38: could not set prop tone of <ui-badge>: Value "error" is not one of info|warning`, propErr.Error())
+	missingErr, ok := w.Errors()[1].(*MissingPropError)
+	require.True(t, ok)
+	require.Equal(t, "app-root:1:38: missing required prop label of <ui-badge>", missingErr.Error())
+
+	html, err := render.String(cmp)
+	require.Nil(t, err)
+	require.Equal(t, "<div><span>info: ok</span><span>info: </span></div>", html)
+}
+
+type UserBadge struct {
+	UserID int `wasm:"prop"`
+}
+
+func (c *UserBadge) Init() error { return nil }
+
+type UserPage struct {
+	UserID int
+}
+
+func (c *UserPage) Init() error {
+	c.UserID = 7
+	return nil
+}
+
+func TestPropsEvaluatedInParentScope(t *testing.T) {
+	for name, cmp := range map[string]component.ComponentInput{"user-badge": &UserBadge{}, "user-page": &UserPage{}} {
+		wrapper, err := component.Wasmify(cmp)
+		require.Nil(t, err)
+		registry.Register(name, wrapper)
+		registry.RegisterTemplate(name, name+"-template")
+	}
+	dom.RegisterMockTemplate("user-badge-template", `<span>{{ UserID }}</span>`)
+	dom.RegisterMockTemplate("user-page-template", `<user-badge :user-id="UserID"></user-badge>`)
+
+	w := walkString(t, `<user-page></user-page>`)
+	cmp := w.WalkAST(scope.Empty())
+	checkWalkErrors(t, w)
+
+	html, err := render.String(cmp)
+	require.Nil(t, err)
+	require.Equal(t, "<span>7</span>", html)
+}
+
+type Panel struct {
+	Title string
+}
+
+func (c *Panel) Init() error {
+	c.Title = "panel"
+	return nil
+}
+
+func (c *Panel) HandleRename(e *event.Event) {
+	c.Title = "renamed panel"
+}
+
+type Screen struct {
+	Title string
+}
+
+func (c *Screen) Init() error {
+	c.Title = "screen"
+	return nil
+}
+
+func (c *Screen) HandleRename(e *event.Event) {
+	c.Title = "renamed screen"
+}
+
+func TestSlotsUseParentScope(t *testing.T) {
+	for name, cmp := range map[string]component.ComponentInput{"ui-panel": &Panel{}, "ui-screen": &Screen{}} {
+		wrapper, err := component.Wasmify(cmp)
+		require.Nil(t, err)
+		registry.Register(name, wrapper)
+		registry.RegisterTemplate(name, name+"-template")
+	}
+	dom.RegisterMockTemplate("ui-panel-template", `<div>{{ Title }}/<slot></slot></div>`)
+	dom.RegisterMockTemplate("ui-screen-template", `<ui-panel><b @click="HandleRename">{{ Title }}</b></ui-panel>`)
+
+	w := walkString(t, `<ui-screen></ui-screen>`)
+	cmp := w.WalkAST(scope.Empty())
+	checkWalkErrors(t, w)
+
+	html, err := render.String(cmp)
+	require.Nil(t, err)
+	require.Equal(t, "<div>panel/<b>screen</b></div>", html)
+
+	b := cmp[0].Children()[0].Body()[0]
+	require.True(t, b.Handle("click", &event.Event{}))
+	cmp[0].Notify()
+
+	html, err = render.String(cmp)
+	require.Nil(t, err)
+	require.Equal(t, "<div>panel/<b>renamed screen</b></div>", html)
+}
+
+type Drawer struct {
+	Open  bool
+	Items []Todo
+}
+
+func (c *Drawer) Init() error { return nil }
+
+func TestErrorsInTemplatesBuiltLater(t *testing.T) {
+	wrapper, err := component.Wasmify(&Drawer{})
+	require.Nil(t, err)
+	registry.Register("ui-drawer", wrapper)
+	registry.RegisterTemplate("ui-drawer", "ui-drawer-template")
+	dom.RegisterMockTemplate("ui-drawer-template", `<div>
+		<p w-if="Open">{{ Typo }}</p>
+		<p w-else @click="HandleMissing"><b w-if="Open">{{ Other }}</b></p>
+		<i w-for="it in Items" :key="it.Nmae">{{ Missing }} {{ it.Title }}</i>
+		<ul w-if="Open"><li w-for="it in Items">{{ it.Name }}</li></ul>
+	</div>`)
+
+	dom.RegisterMockTemplate("app-root", `<ui-drawer></ui-drawer>`)
+	w := NewByID("app-root")
+	w.WalkAST(scope.Empty())
+
+	messages := make([]string, 0)
+	for _, err := range w.Errors() {
+		messages = append(messages, err.Error())
+	}
+	require.Len(t, messages, 6, "%v", messages)
+	require.Contains(t, messages[0], "unknown field Typo")
+	require.Contains(t, messages[1], "unknown handler HandleMissing")
+	require.Contains(t, messages[2], "unknown field Other")
+	require.Contains(t, messages[3], "has no field Nmae")
+	require.Contains(t, messages[4], "unknown field Missing")
+	require.Contains(t, messages[5], "has no field Name")
+}
+
+func TestExpressionErrorsHavePositions(t *testing.T) {
+	registerTodoList(t, `<ul>
+		<p>{{ Todos[0].Nmae }}</p>
+		<p :title="Todos[0].Title == 3"></p>
+		<li w-for="todo of Todos"></li>
+	</ul>`)
+
+	dom.RegisterMockTemplate("app-root", `<todo-list></todo-list>`)
+	w := NewByID("app-root")
+	w.WalkAST(scope.Empty())
+
+	require.Len(t, w.Errors(), 3)
+	for _, err := range w.Errors() {
+		exprErr, ok := err.(*ExpressionError)
+		require.True(t, ok, err.Error())
+		require.Equal(t, "todo-list-template", exprErr.TemplateID)
+	}
+	require.Equal(t, `todo-list-template:2:6: type walker.Todo has no field Nmae in expression "Todos[0].Nmae" at offset 9`, w.Errors()[0].Error())
+	require.Equal(t, `todo-list-template:3:3: mismatched types string and int in expression "Todos[0].Title == 3" at offset 15`, w.Errors()[1].Error())
+	require.Equal(t, `todo-list-template:4:3: invalid w-for expression "todo of Todos", expected "item in Items"`, w.Errors()[2].Error())
+}